@@ -0,0 +1,48 @@
+package ebitest
+
+import "image"
+
+// Anchor is a point of a Selector, defined as a fraction (0-1) of its
+// size from the top-left corner plus an offset in pixels
+type Anchor struct {
+	// X and Y are the fractions of the width and height
+	X, Y float64
+
+	// OffsetX and OffsetY are the pixels added to the point
+	OffsetX, OffsetY int
+}
+
+// The Anchors of the corners, the sides and the center
+var (
+	TopLeft     = Anchor{X: 0, Y: 0}
+	Top         = Anchor{X: 0.5, Y: 0}
+	TopRight    = Anchor{X: 1, Y: 0}
+	Left        = Anchor{X: 0, Y: 0.5}
+	Center      = Anchor{X: 0.5, Y: 0.5}
+	Right       = Anchor{X: 1, Y: 0.5}
+	BottomLeft  = Anchor{X: 0, Y: 1}
+	Bottom      = Anchor{X: 0.5, Y: 1}
+	BottomRight = Anchor{X: 1, Y: 1}
+)
+
+// At returns an Anchor at the fractions x and y of the size, like
+// At(0.25, 0.5) for the first quarter of a slider
+func At(x, y float64) Anchor {
+	return Anchor{X: x, Y: y}
+}
+
+// Offset returns a copy of the Anchor moved dx and dy pixels,
+// like Left.Offset(-10, 0) for a checkbox on the left of a label
+func (a Anchor) Offset(dx, dy int) Anchor {
+	a.OffsetX += dx
+	a.OffsetY += dy
+	return a
+}
+
+// point returns the point of the Anchor on r, the fraction 1 is
+// the last pixel so the Anchors are always inside of r
+func (a Anchor) point(r image.Rectangle) image.Point {
+	x := r.Min.X + min(int(a.X*float64(r.Dx())), max(0, r.Dx()-1))
+	y := r.Min.Y + min(int(a.Y*float64(r.Dy())), max(0, r.Dy()-1))
+	return image.Pt(x+a.OffsetX, y+a.OffsetY)
+}
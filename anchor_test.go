@@ -0,0 +1,35 @@
+package ebitest
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchorPoint(t *testing.T) {
+	r := image.Rect(10, 20, 31, 30)
+	tcs := []struct {
+		name string
+		a    Anchor
+		exp  image.Point
+	}{
+		{name: "TopLeft", a: TopLeft, exp: image.Pt(10, 20)},
+		{name: "Center", a: Center, exp: image.Pt(20, 25)},
+		{name: "BottomRight", a: BottomRight, exp: image.Pt(30, 29)},
+		{name: "Right", a: Right, exp: image.Pt(30, 25)},
+		{name: "At", a: At(0.25, 0.5), exp: image.Pt(15, 25)},
+		{name: "Offset", a: Left.Offset(-10, 2).Offset(1, 0), exp: image.Pt(1, 27)},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, tc.a.point(r))
+		})
+	}
+
+	// The Center is the same as the center of the Selector
+	s := &Selector{rect: r}
+	cx, cy := s.center()
+	assert.Equal(t, image.Pt(cx, cy), Center.point(r))
+}
@@ -0,0 +1,119 @@
+package ebitest
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+const (
+	// minColorRegionCoverage is the fraction of the rectangle of a region
+	// that has to be of the color, so the rounded corners of a button are
+	// accepted but not the shapes that are not a rectangle like an L
+	minColorRegionCoverage = 0.9
+)
+
+// colorRegion finds the connected regions of a color
+type colorRegion struct {
+	color   color.NRGBA
+	minSize image.Point
+}
+
+// ColorRegion returns a Selector that finds the rectangular regions of connected pixels
+// of the color c (with the ColorTolerance) that are at least of minSize. The Rec of the
+// found selectors is the rectangle that contains all the pixels of the region, which have
+// to cover at least 90% of it, and the Score is the fraction of it that they cover
+func ColorRegion(c color.Color, minSize image.Point) *Selector {
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	nc.A = 255
+
+	// The image is only used to display it on the dumps
+	sw := image.NewNRGBA(image.Rect(0, 0, max(1, minSize.X), max(1, minSize.Y)))
+	for x := range sw.Bounds().Dx() {
+		for y := range sw.Bounds().Dy() {
+			sw.SetNRGBA(x, y, nc)
+		}
+	}
+
+	s := NewFromImage(sw)
+	s.finder = &colorRegion{
+		color:   nc,
+		minSize: minSize,
+	}
+	return s
+}
+
+// find implements finder
+func (cr *colorRegion) find(e *Ebitest, bsel *Selector, region *image.NRGBA, all bool) ([]*Selector, *Selector) {
+	ct := e.colorTolerance(bsel)
+	rb := region.Bounds()
+	w, h := rb.Dx(), rb.Dy()
+
+	matches := make([]bool, w*h)
+	for x := range w {
+		for y := range h {
+			matches[y*w+x] = pixelEqual(nrgbaAt(region, x, y), cr.color, ct)
+		}
+	}
+
+	// Flood fill each not visited matching pixel to find
+	// the rectangle that contains all the region
+	type foundRegion struct {
+		rect     image.Rectangle
+		coverage float64
+	}
+	regions := make([]foundRegion, 0)
+	visited := make([]bool, w*h)
+	stack := make([]image.Point, 0)
+	for i, ok := range matches {
+		if !ok || visited[i] {
+			continue
+		}
+		p := image.Pt(i%w, i/w)
+		rect := image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))}
+		var pixels int
+		visited[i] = true
+		stack = append(stack[:0], p)
+		for len(stack) != 0 {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			pixels++
+			rect = rect.Union(image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))})
+			for _, n := range []image.Point{{p.X - 1, p.Y}, {p.X + 1, p.Y}, {p.X, p.Y - 1}, {p.X, p.Y + 1}} {
+				if n.X < 0 || n.Y < 0 || n.X >= w || n.Y >= h {
+					continue
+				}
+				ni := n.Y*w + n.X
+				if matches[ni] && !visited[ni] {
+					visited[ni] = true
+					stack = append(stack, n)
+				}
+			}
+		}
+		if rect.Dx() < cr.minSize.X || rect.Dy() < cr.minSize.Y {
+			continue
+		}
+		coverage := float64(pixels) / float64(rect.Dx()*rect.Dy())
+		if coverage < minColorRegionCoverage {
+			continue
+		}
+		regions = append(regions, foundRegion{rect: rect.Add(rb.Min), coverage: coverage})
+	}
+
+	// Sorted by column and then by row as the other selectors
+	sort.Slice(regions, func(i, j int) bool {
+		if regions[i].rect.Min.X != regions[j].rect.Min.X {
+			return regions[i].rect.Min.X < regions[j].rect.Min.X
+		}
+		return regions[i].rect.Min.Y < regions[j].rect.Min.Y
+	})
+	if !all && len(regions) > 1 {
+		regions = regions[:1]
+	}
+
+	sels := make([]*Selector, 0, len(regions))
+	for _, r := range regions {
+		sels = append(sels, e.found(bsel, r.rect, r.coverage))
+	}
+	return sels, bsel
+}
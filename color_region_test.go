@@ -0,0 +1,65 @@
+package ebitest
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorRegionFind(t *testing.T) {
+	sc := image.NewNRGBA(image.Rect(0, 0, 60, 40))
+	red := color.NRGBA{255, 0, 0, 255}
+	fill := func(r image.Rectangle, c color.NRGBA) {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				sc.SetNRGBA(x, y, c)
+			}
+		}
+	}
+	fill(sc.Bounds(), color.NRGBA{0, 0, 0, 255})
+	// An L shape is not a region as it does not fill its rectangle
+	fill(image.Rect(45, 5, 55, 10), red)
+	fill(image.Rect(45, 10, 48, 20), red)
+	// A region with the corners rounded
+	fill(image.Rect(30, 5, 40, 20), red)
+	for _, p := range []image.Point{{30, 5}, {39, 5}, {30, 19}, {39, 19}} {
+		sc.SetNRGBA(p.X, p.Y, color.NRGBA{0, 0, 0, 255})
+	}
+	// A region too small
+	fill(image.Rect(5, 5, 7, 7), red)
+	// A region with a slightly different color
+	fill(image.Rect(5, 25, 15, 35), color.NRGBA{250, 5, 0, 255})
+
+	e := &Ebitest{}
+	find := func(sel *Selector, region *image.NRGBA, all bool) []*Selector {
+		sels, _ := sel.finder.find(e, sel, region, all)
+		return sels
+	}
+	rects := func(sels []*Selector) []image.Rectangle {
+		rs := make([]image.Rectangle, 0, len(sels))
+		for _, s := range sels {
+			rs = append(rs, s.Rec())
+		}
+		return rs
+	}
+
+	sel := ColorRegion(red, image.Pt(3, 3))
+	sels := find(sel, sc, true)
+	assert.Equal(t, []image.Rectangle{image.Rect(30, 5, 40, 20)}, rects(sels))
+	assert.InDelta(t, 146.0/150.0, sels[0].Score(), 0.0001)
+
+	// Each part of the L is a region if it's not connected
+	fill(image.Rect(45, 10, 48, 11), color.NRGBA{0, 0, 0, 255})
+	assert.Equal(t, []image.Rectangle{image.Rect(30, 5, 40, 20), image.Rect(45, 5, 55, 10), image.Rect(45, 11, 48, 20)}, rects(find(sel, sc, true)))
+	fill(image.Rect(45, 5, 55, 20), color.NRGBA{0, 0, 0, 255})
+
+	sel = ColorRegion(red, image.Pt(3, 3)).WithColorTolerance(ColorTolerance{Delta: 5})
+	assert.Equal(t, []image.Rectangle{image.Rect(5, 25, 15, 35), image.Rect(30, 5, 40, 20)}, rects(find(sel, sc, true)))
+	assert.Equal(t, []image.Rectangle{image.Rect(5, 25, 15, 35)}, rects(find(sel, sc, false)))
+
+	// The regions keep the position of the screen when searching on a part of it
+	region := sc.SubImage(image.Rect(20, 0, 60, 40)).(*image.NRGBA)
+	assert.Equal(t, []image.Rectangle{image.Rect(30, 5, 40, 20)}, rects(find(sel, region, true)))
+}
@@ -0,0 +1,193 @@
+package ebitest
+
+import (
+	"image/color"
+	"math"
+)
+
+// ColorTolerance defines how much 2 colors can differ and still be
+// considered the same when matching
+type ColorTolerance struct {
+	// Delta is the maximum difference allowed on each RGB channel (0-255)
+	Delta uint8
+
+	// DeltaE is the maximum CIEDE2000 distance allowed between the colors.
+	// If set it's used instead of Delta
+	DeltaE float64
+}
+
+// isExact checks if the tolerance requires the colors to be exactly the same
+func (ct ColorTolerance) isExact() bool {
+	return ct.Delta == 0 && ct.DeltaE == 0
+}
+
+// equalColors checks if c1 and c2 have the same RGB within the tolerance ct
+func equalColors(c1, c2 color.Color, ct ColorTolerance) bool {
+	return pixelEqual(
+		color.NRGBAModel.Convert(c1).(color.NRGBA),
+		color.NRGBAModel.Convert(c2).(color.NRGBA),
+		ct,
+	)
+}
+
+// pixelEqualAlpha checks if c1 and c2 are the same as with pixelEqual and also
+// have the same alpha within the Delta of ct, so a transparent pixel of the
+// screen is not equal to an opaque color with any RGB
+func pixelEqualAlpha(c1, c2 color.NRGBA, ct ColorTolerance) bool {
+	return pixelEqual(c1, c2, ct) && channelDiff(uint32(c1.A), uint32(c2.A)) <= uint32(ct.Delta)
+}
+
+// pixelEqual checks if c1 and c2 have the same RGB within the tolerance ct,
+// the alpha is ignored so semi-transparent colors can also be compared
+func pixelEqual(c1, c2 color.NRGBA, ct ColorTolerance) bool {
+	if ct.isExact() {
+		return c1.R == c2.R && c1.G == c2.G && c1.B == c2.B
+	}
+
+	if ct.DeltaE != 0 {
+		return ciede2000(nrgbaToLab(c1), nrgbaToLab(c2)) <= ct.DeltaE
+	}
+
+	d := uint32(ct.Delta)
+	return channelDiff(uint32(c1.R), uint32(c2.R)) <= d &&
+		channelDiff(uint32(c1.G), uint32(c2.G)) <= d &&
+		channelDiff(uint32(c1.B), uint32(c2.B)) <= d
+}
+
+// nrgbaToLab converts c to CIE L*a*b* ignoring the alpha
+func nrgbaToLab(c color.NRGBA) lab {
+	return toLab(uint32(c.R)*0x101, uint32(c.G)*0x101, uint32(c.B)*0x101)
+}
+
+// channelDiff returns the absolute difference between a and b
+func channelDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// lab is a color on the CIE L*a*b* space
+type lab struct {
+	l, a, b float64
+}
+
+// toLab converts the 16 bit RGB values (as returned by color.Color.RGBA)
+// to CIE L*a*b* using the D65 white point
+func toLab(r, g, b uint32) lab {
+	lr := srgbToLinear(float64(r) / 0xffff)
+	lg := srgbToLinear(float64(g) / 0xffff)
+	lb := srgbToLinear(float64(b) / 0xffff)
+
+	x := (0.4124564*lr + 0.3575761*lg + 0.1804375*lb) / 0.95047
+	y := (0.2126729*lr + 0.7151522*lg + 0.0721750*lb) / 1.0
+	z := (0.0193339*lr + 0.1191920*lg + 0.9503041*lb) / 1.08883
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+
+	return lab{
+		l: 116*fy - 16,
+		a: 500 * (fx - fy),
+		b: 200 * (fy - fz),
+	}
+}
+
+// srgbToLinear removes the sRGB gamma from v
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// labF is the f(t) function of the XYZ to L*a*b* conversion
+func labF(t float64) float64 {
+	const delta = 6.0 / 29.0
+	if t > delta*delta*delta {
+		return math.Cbrt(t)
+	}
+	return t/(3*delta*delta) + 4.0/29.0
+}
+
+// ciede2000 returns the CIEDE2000 color difference between c1 and c2
+func ciede2000(c1, c2 lab) float64 {
+	const (
+		kl, kc, kh = 1.0, 1.0, 1.0
+		pow25To7   = 6103515625.0 // 25^7
+	)
+
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	deg := func(rad float64) float64 { return rad * 180 / math.Pi }
+
+	cb := (math.Hypot(c1.a, c1.b) + math.Hypot(c2.a, c2.b)) / 2
+	cb7 := math.Pow(cb, 7)
+	g := 0.5 * (1 - math.Sqrt(cb7/(cb7+pow25To7)))
+
+	a1p := (1 + g) * c1.a
+	a2p := (1 + g) * c2.a
+	c1p := math.Hypot(a1p, c1.b)
+	c2p := math.Hypot(a2p, c2.b)
+
+	hue := func(b, ap float64) float64 {
+		if b == 0 && ap == 0 {
+			return 0
+		}
+		h := deg(math.Atan2(b, ap))
+		if h < 0 {
+			h += 360
+		}
+		return h
+	}
+	h1p := hue(c1.b, a1p)
+	h2p := hue(c2.b, a2p)
+
+	dLp := c2.l - c1.l
+	dCp := c2p - c1p
+
+	var dhp float64
+	if c1p*c2p != 0 {
+		dhp = h2p - h1p
+		if dhp > 180 {
+			dhp -= 360
+		} else if dhp < -180 {
+			dhp += 360
+		}
+	}
+	dHp := 2 * math.Sqrt(c1p*c2p) * math.Sin(rad(dhp/2))
+
+	lbp := (c1.l + c2.l) / 2
+	cbp := (c1p + c2p) / 2
+
+	hbp := h1p + h2p
+	if c1p*c2p != 0 {
+		if math.Abs(h1p-h2p) > 180 {
+			if hbp < 360 {
+				hbp += 360
+			} else {
+				hbp -= 360
+			}
+		}
+		hbp /= 2
+	}
+
+	t := 1 -
+		0.17*math.Cos(rad(hbp-30)) +
+		0.24*math.Cos(rad(2*hbp)) +
+		0.32*math.Cos(rad(3*hbp+6)) -
+		0.20*math.Cos(rad(4*hbp-63))
+
+	dTheta := 30 * math.Exp(-math.Pow((hbp-275)/25, 2))
+	cbp7 := math.Pow(cbp, 7)
+	rc := 2 * math.Sqrt(cbp7/(cbp7+pow25To7))
+	lbp50 := (lbp - 50) * (lbp - 50)
+	sl := 1 + (0.015*lbp50)/math.Sqrt(20+lbp50)
+	sc := 1 + 0.045*cbp
+	sh := 1 + 0.015*cbp*t
+	rt := -math.Sin(rad(2*dTheta)) * rc
+
+	fl := dLp / (kl * sl)
+	fc := dCp / (kc * sc)
+	fh := dHp / (kh * sh)
+
+	return math.Sqrt(fl*fl + fc*fc + fh*fh + rt*fc*fh)
+}
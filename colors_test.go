@@ -0,0 +1,38 @@
+package ebitest
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIEDE2000(t *testing.T) {
+	// Reference values from Sharma, Wu and Dalal
+	tcs := []struct {
+		c1, c2 lab
+		de     float64
+	}{
+		{c1: lab{50, 2.6772, -79.7751}, c2: lab{50, 0, -82.7485}, de: 2.0425},
+		{c1: lab{50, 3.1571, -77.2803}, c2: lab{50, 0, -82.7485}, de: 2.8615},
+		{c1: lab{50, 0, 0}, c2: lab{50, -1, 2}, de: 2.3669},
+		{c1: lab{50, 2.5, 0}, c2: lab{73, 25, -18}, de: 27.1492},
+		{c1: lab{2.0776, 0.0795, -1.1350}, c2: lab{0.9033, -0.0636, -0.5514}, de: 0.9082},
+	}
+
+	for _, tc := range tcs {
+		assert.InDelta(t, tc.de, ciede2000(tc.c1, tc.c2), 0.0001)
+	}
+}
+
+func TestEqualColors(t *testing.T) {
+	c1 := color.NRGBA{100, 100, 100, 255}
+	c2 := color.NRGBA{103, 98, 100, 255}
+
+	assert.True(t, equalColors(c1, c1, ColorTolerance{}))
+	assert.False(t, equalColors(c1, c2, ColorTolerance{}))
+	assert.False(t, equalColors(c1, c2, ColorTolerance{Delta: 2}))
+	assert.True(t, equalColors(c1, c2, ColorTolerance{Delta: 3}))
+	assert.True(t, equalColors(c1, c2, ColorTolerance{DeltaE: 4}))
+	assert.False(t, equalColors(c1, c2, ColorTolerance{DeltaE: 0.5}))
+}
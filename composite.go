@@ -0,0 +1,301 @@
+package ebitest
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// compositeDumpGap is the space between the images
+	// of the parts of a composite on the dumps
+	compositeDumpGap = 2
+)
+
+// compositeKind is how the parts of a composite are combined
+type compositeKind int
+
+const (
+	compositeAnyOf compositeKind = iota
+	compositeAllOf
+	compositeNot
+	compositeSequence
+)
+
+// composite finds a combination of parts
+type composite struct {
+	kind  compositeKind
+	parts []interface{}
+
+	// found are where each part was found on the last search, or
+	// emptyRec if not, it's only used on the failure messages and dumps
+	found []image.Rectangle
+}
+
+// AnyOf returns a Selector that finds the first of the parts that is present, so it can be used for the
+// screens that can be on different states. With GetAll it returns the instances of all the parts.
+// The parts can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func AnyOf(parts ...interface{}) *Selector {
+	return newComposite(compositeAnyOf, parts...)
+}
+
+// AllOf returns a Selector that finds all the parts, and its Rec is the one that contains all of them.
+// The parts are as on AnyOf
+func AllOf(parts ...interface{}) *Selector {
+	return newComposite(compositeAllOf, parts...)
+}
+
+// Not returns a Selector that is found when the part is not present, and its Rec is the area
+// that was searched. It's meant to be used with AllOf, like AllOf("Ready", Not("Loading")).
+// The part is as on AnyOf
+func Not(part interface{}) *Selector {
+	return newComposite(compositeNot, part)
+}
+
+// Sequence returns a Selector that finds all the parts one after the other in reading order
+// (left-to-right and top-to-bottom), like the options of a menu, and its Rec is the one that
+// contains all of them. The parts are as on AnyOf
+func Sequence(parts ...interface{}) *Selector {
+	return newComposite(compositeSequence, parts...)
+}
+
+// newComposite returns a Selector that combines the parts as defined by k
+func newComposite(k compositeKind, parts ...interface{}) *Selector {
+	return &Selector{
+		finder: &composite{
+			kind:  k,
+			parts: parts,
+		},
+	}
+}
+
+// find implements finder
+func (c *composite) find(e *Ebitest, bsel *Selector, region *image.NRGBA, all bool) ([]*Selector, *Selector) {
+	// The failure Selector has all the images of the parts
+	// and where they were found for the messages and dumps
+	fc := *c
+	fc.found = make([]image.Rectangle, len(c.parts))
+	fsel := bsel.base()
+	fsel.finder = &fc
+
+	// Sequence needs all the instances to find the ones in order
+	// and AnyOf returns all the instances of all the parts
+	partsAll := c.kind == compositeSequence || (c.kind == compositeAnyOf && all)
+
+	imgs := make([]image.Image, 0, len(c.parts))
+	found := make([][]*Selector, len(c.parts))
+	for i, p := range c.parts {
+		sels, psel := e.findSelectors(region, p, partsAll, emptyRec)
+		if psel.img != nil {
+			imgs = append(imgs, psel.img)
+		}
+		found[i] = sels
+		if len(sels) != 0 {
+			fc.found[i] = sels[0].rect
+		}
+		if c.kind == compositeAnyOf && !all && len(sels) != 0 {
+			return sels[:1], fsel
+		}
+	}
+	fsel.img = stackImages(imgs)
+
+	switch c.kind {
+	case compositeAnyOf:
+		sels := make([]*Selector, 0)
+		for _, fs := range found {
+			for _, s := range fs {
+				if !overlapsAny(s.rect, sels) {
+					sels = append(sels, s)
+				}
+			}
+		}
+		return sels, fsel
+	case compositeAllOf:
+		rects := make([]image.Rectangle, 0, len(found))
+		for i, fs := range found {
+			if len(fs) == 0 {
+				return nil, fsel
+			}
+			// The Not parts are all the region so they
+			// are not part of the Rec if there are others
+			if !isNot(c.parts[i]) {
+				rects = append(rects, fs[0].rect)
+			}
+		}
+		if len(rects) == 0 {
+			rects = append(rects, region.Bounds())
+		}
+		return []*Selector{c.union(e, fsel, rects, found)}, fsel
+	case compositeNot:
+		if len(found[0]) != 0 {
+			return nil, fsel
+		}
+		return []*Selector{e.found(c.withFinder(fsel), region.Bounds(), 1)}, fsel
+	case compositeSequence:
+		rects, ok := sequenceRects(found)
+		if !ok {
+			// The found of the failure are the ones that were in order
+			copy(fc.found, rects)
+			for i := len(rects); i < len(fc.found); i++ {
+				fc.found[i] = emptyRec
+			}
+			return nil, fsel
+		}
+		return []*Selector{c.union(e, fsel, rects, found)}, fsel
+	}
+
+	return nil, fsel
+}
+
+// union returns the found Selector that contains all the rects with
+// the lowest score of the found parts at those rects
+func (c *composite) union(e *Ebitest, fsel *Selector, rects []image.Rectangle, found [][]*Selector) *Selector {
+	var rect image.Rectangle
+	for _, r := range rects {
+		rect = rect.Union(r)
+	}
+
+	score := 1.0
+	for _, fs := range found {
+		for _, s := range fs {
+			if slices.Contains(rects, s.rect) {
+				score = min(score, s.score)
+			}
+		}
+	}
+	return e.found(c.withFinder(fsel), rect, score)
+}
+
+// withFinder returns a copy of sel with c as finder so the
+// found Selector can be searched again
+func (c *composite) withFinder(sel *Selector) *Selector {
+	ns := *sel
+	ns.finder = c
+	return &ns
+}
+
+// sequenceRects returns the first instance of each part that is after the
+// previous one in reading order and if all of them were found. If not the
+// returned are the ones found in order until the first missing one
+func sequenceRects(found [][]*Selector) ([]image.Rectangle, bool) {
+	rects := make([]image.Rectangle, 0, len(found))
+	var prev image.Rectangle
+	for i, fs := range found {
+		cands := make([]image.Rectangle, 0, len(fs))
+		for _, s := range fs {
+			if i == 0 || afterInReading(prev, s.rect) {
+				cands = append(cands, s.rect)
+			}
+		}
+		if len(cands) == 0 {
+			return rects, false
+		}
+		sort.SliceStable(cands, func(i, j int) bool {
+			if cands[i].Min.Y != cands[j].Min.Y {
+				return cands[i].Min.Y < cands[j].Min.Y
+			}
+			return cands[i].Min.X < cands[j].Min.X
+		})
+		prev = cands[0]
+		rects = append(rects, prev)
+	}
+	return rects, true
+}
+
+// afterInReading checks if r is after prev in reading order, which is
+// on the same row at the right or on a row below
+func afterInReading(prev, r image.Rectangle) bool {
+	if r.Min.Y >= prev.Max.Y {
+		return true
+	}
+	sameRow := r.Min.Y < prev.Max.Y && prev.Min.Y < r.Max.Y
+	return sameRow && r.Min.X >= prev.Max.X
+}
+
+// describe implements describer
+func (c *composite) describe() string {
+	var d string
+	switch c.kind {
+	case compositeAnyOf:
+		d = "with any of the parts:"
+	case compositeAllOf:
+		d = "with all of the parts:"
+	case compositeNot:
+		d = "with not the part:"
+	case compositeSequence:
+		d = "with the parts in sequence:"
+	}
+
+	lines := []string{d}
+	for i, p := range c.parts {
+		var state string
+		if i < len(c.found) && c.found[i] != emptyRec {
+			state = fmt.Sprintf("found at %v", c.found[i])
+		} else {
+			state = "not found"
+		}
+		lines = append(lines, fmt.Sprintf("  [%d] %s: %s", i, partName(p), state))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dumpRects implements rectsDumper
+func (c *composite) dumpRects() []image.Rectangle {
+	rects := make([]image.Rectangle, 0, len(c.found))
+	for _, r := range c.found {
+		if r != emptyRec {
+			rects = append(rects, r)
+		}
+	}
+	return rects
+}
+
+// isNot checks if the part p is a Not
+func isNot(p interface{}) bool {
+	s, ok := p.(*Selector)
+	if !ok {
+		return false
+	}
+	c, ok := s.finder.(*composite)
+	return ok && c.kind == compositeNot
+}
+
+// partName returns a name to identify the part p on the messages
+func partName(p interface{}) string {
+	switch v := p.(type) {
+	case string:
+		return strconv.Quote(v)
+	case *TextSelector:
+		return strconv.Quote(v.txt)
+	case *Selector:
+		if v.name != "" {
+			return strconv.Quote(v.name)
+		}
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+// stackImages returns an image with all the imgs one below the other
+func stackImages(imgs []image.Image) image.Image {
+	var w, h int
+	for i, img := range imgs {
+		w = max(w, img.Bounds().Dx())
+		h += img.Bounds().Dy()
+		if i != 0 {
+			h += compositeDumpGap
+		}
+	}
+
+	stack := image.NewNRGBA(image.Rect(0, 0, max(1, w), max(1, h)))
+	var y int
+	for _, img := range imgs {
+		b := img.Bounds()
+		draw.Draw(stack, image.Rect(0, y, b.Dx(), y+b.Dy()), img, b.Min, draw.Src)
+		y += b.Dy() + compositeDumpGap
+	}
+	return stack
+}
@@ -0,0 +1,57 @@
+package ebitest
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceRects(t *testing.T) {
+	sels := func(rs ...image.Rectangle) []*Selector {
+		ss := make([]*Selector, 0, len(rs))
+		for _, r := range rs {
+			ss = append(ss, &Selector{rect: r})
+		}
+		return ss
+	}
+	first := image.Rect(10, 10, 30, 20)
+	second := image.Rect(40, 12, 60, 22)
+	third := image.Rect(0, 30, 20, 40)
+
+	rects, ok := sequenceRects([][]*Selector{
+		sels(first),
+		sels(image.Rect(0, 0, 5, 5), second),
+		sels(third, image.Rect(50, 50, 60, 60)),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, []image.Rectangle{first, second, third}, rects)
+
+	// The third is before the second one
+	rects, ok = sequenceRects([][]*Selector{
+		sels(first),
+		sels(third),
+		sels(second),
+	})
+	assert.False(t, ok)
+	assert.Equal(t, []image.Rectangle{first, third}, rects)
+}
+
+func TestCompositeDescribe(t *testing.T) {
+	c := AllOf("Ready", Text("Play"), image.NewNRGBA(image.Rect(0, 0, 1, 1))).finder.(*composite)
+	c.found = []image.Rectangle{image.Rect(0, 0, 10, 10), emptyRec, emptyRec}
+	assert.Equal(t, `with all of the parts:
+  [0] "Ready": found at (0,0)-(10,10)
+  [1] "Play": not found
+  [2] *image.NRGBA: not found`, c.describe())
+	assert.Equal(t, []image.Rectangle{image.Rect(0, 0, 10, 10)}, c.dumpRects())
+}
+
+func TestStackImages(t *testing.T) {
+	img := stackImages([]image.Image{
+		image.NewNRGBA(image.Rect(0, 0, 10, 5)),
+		image.NewNRGBA(image.Rect(0, 0, 4, 8)),
+	})
+	assert.Equal(t, image.Rect(0, 0, 10, 5+compositeDumpGap+8), img.Bounds())
+	assert.Equal(t, image.Rect(0, 0, 1, 1), stackImages(nil).Bounds())
+}
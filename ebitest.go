@@ -10,7 +10,11 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -22,10 +26,38 @@ import (
 const (
 	baseDumpFoler    = "_ebitest_dump/"
 	findAllSelectors = true
+
+	// defaultMatchThreshold is the score needed to match if
+	// none is configured, which means an exact match
+	defaultMatchThreshold = 1.0
+
+	// defaultAlphaThreshold is the minimum alpha of the pixels
+	// to compare if none is configured, so only the opaque ones
+	defaultAlphaThreshold = 255
+
+	// minNearMissScore is the minimum score a location has to
+	// have to be reported as the closest match on a failure
+	minNearMissScore = 0.5
 )
 
 var (
 	emptyRec image.Rectangle
+
+	// selectorRectColor is the color used on the dumps to highlight the selector
+	selectorRectColor = color.RGBA{255, 0, 0, 255}
+
+	// scopeRectColor is the color used on the dumps to highlight the scope of the search
+	scopeRectColor = color.RGBA{0, 0, 255, 255}
+
+	// foundRectColor is the color used on the dumps to highlight the found parts of the selector
+	foundRectColor = color.RGBA{0, 255, 0, 255}
+
+	// ignoredDumpColor is the color used on the dumps for the ignored pixels of the selector
+	ignoredDumpColor = color.NRGBA{128, 128, 128, 255}
+
+	// mismatchDumpColor is the color used on the dumps for the pixels of
+	// the selector that do not match the screen with the ColorTolerance
+	mismatchDumpColor = color.NRGBA{255, 0, 255, 255}
 )
 
 type Ebitest struct {
@@ -36,12 +68,29 @@ type Ebitest struct {
 	endGameChan chan struct{}
 
 	options options
+
+	// registry are the selectors defined by name
+	mxRegistry sync.Mutex
+	registry   map[string]*Selector
 }
 
 type options struct {
 	face            text.Face
+	faces           []text.Face
 	color           color.Color
 	dumpErrorImages bool
+	colorTolerance  ColorTolerance
+	matchThreshold  *float64
+	searchWorkers   int
+	scales          []float64
+	autoScale       bool
+	scaleFilter     ScaleFilter
+	matcher         Matcher
+	logger          *log.Logger
+	alphaThreshold  uint8
+	anyTextColor    bool
+	textRecognizer  TextRecognizer
+	textSubPixels   int
 }
 
 type optionsFn func(*options)
@@ -53,6 +102,14 @@ func WithFace(f text.Face) optionsFn {
 	}
 }
 
+// WithFaces set's multiple default Faces for when checking for texts,
+// all of them are tried as if they were the same text
+func WithFaces(fs ...text.Face) optionsFn {
+	return func(o *options) {
+		o.faces = fs
+	}
+}
+
 // WithColor set's the default Color for when checking for texts
 func WithColor(c color.Color) optionsFn {
 	return func(o *options) {
@@ -60,6 +117,23 @@ func WithColor(c color.Color) optionsFn {
 	}
 }
 
+// WithAnyTextColor makes the texts to match by the shape of the
+// glyphs regardless of the color they have, so WithColor is not needed
+func WithAnyTextColor() optionsFn {
+	return func(o *options) {
+		o.anyTextColor = true
+	}
+}
+
+// WithTextSubPixels makes the texts to be rendered at n*n sub-pixel offsets
+// and matched as one, so they are found even when the game draws them at
+// fractional positions which changes the anti-aliasing. By default is 1
+func WithTextSubPixels(n int) optionsFn {
+	return func(o *options) {
+		o.textSubPixels = n
+	}
+}
+
 // WithDumpErrorImages enables the option to output a custom image when a test fails
 // that has the screen and the image that was tried to match in order to debug it
 func WithDumpErrorImages() optionsFn {
@@ -68,6 +142,83 @@ func WithDumpErrorImages() optionsFn {
 	}
 }
 
+// WithColorTolerance set's the default ColorTolerance used when comparing the
+// colors of the screen with the ones of the selectors
+func WithColorTolerance(ct ColorTolerance) optionsFn {
+	return func(o *options) {
+		o.colorTolerance = ct
+	}
+}
+
+// WithMatchThreshold set's the default minimum score (0-1) that a location of the
+// screen has to have to be considered a match. The score is the fraction of
+// the opaque pixels of the selector that match the screen, by default is 1.
+// The values out of the range are clamped, so 0 matches any location
+func WithMatchThreshold(th float64) optionsFn {
+	return func(o *options) {
+		o.matchThreshold = &th
+	}
+}
+
+// WithSearchWorkers set's the number of goroutines used to search on the screen,
+// by default is the value of runtime.GOMAXPROCS
+func WithSearchWorkers(n int) optionsFn {
+	return func(o *options) {
+		o.searchWorkers = n
+	}
+}
+
+// WithScales set's the default scales at which the selectors are searched,
+// by default only at scale 1
+func WithScales(scales ...float64) optionsFn {
+	return func(o *options) {
+		o.scales = scales
+	}
+}
+
+// WithAutoScale adds to the scales at which the selectors are searched the one of the
+// assets taken from screenshots of the window, which is the size returned by the game
+// Layout over the size of the window in device pixels (with the DeviceScaleFactor)
+// as the screen is captured at the size returned by the Layout
+func WithAutoScale() optionsFn {
+	return func(o *options) {
+		o.autoScale = true
+	}
+}
+
+// WithScaleFilter set's the default ScaleFilter used when scaling the selectors,
+// by default is ScaleNearest
+func WithScaleFilter(f ScaleFilter) optionsFn {
+	return func(o *options) {
+		o.scaleFilter = f
+	}
+}
+
+// WithAlphaThreshold set's the default minimum alpha (1-255) that the pixels of the
+// selectors need to have to be compared with the screen, by default is 255 so only
+// the opaque ones are compared. Lower values allow to compare anti-aliased edges
+func WithAlphaThreshold(a uint8) optionsFn {
+	return func(o *options) {
+		o.alphaThreshold = a
+	}
+}
+
+// WithMatcher set's the default Matcher used to compare the selectors with the screen,
+// by default MatchPixels. With MatchSSIM the default threshold is 0.9
+func WithMatcher(m Matcher) optionsFn {
+	return func(o *options) {
+		o.matcher = m
+	}
+}
+
+// WithLogger set's a logger in which the found selectors, the found texts
+// and the clicks are logged with the name of the selectors if they have one
+func WithLogger(l *log.Logger) optionsFn {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
 func Run(game ebiten.Game, opts ...optionsFn) *Ebitest {
 	ctx, cfn := context.WithCancel(context.TODO())
 	pingPong := NewPingPong()
@@ -110,96 +261,134 @@ func (e *Ebitest) Close() {
 }
 
 // Should checks if selector(s) is present in the game and returns it
-// s can be a: 'string', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+// s can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
 func (e *Ebitest) Should(t *testing.T, s interface{}) (*Selector, bool) {
+	t.Helper()
+	return e.should(t, s, emptyRec)
+}
+
+// ShouldNot checks if selector(s) is not present in the game
+// s can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (e *Ebitest) ShouldNot(t *testing.T, s interface{}) bool {
+	t.Helper()
+	return e.shouldNot(t, s, emptyRec)
+}
+
+// Must checks if selector(s) is present in the game and returns it.
+// If it's not present it'll fail the test
+// s can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (e *Ebitest) Must(t *testing.T, s interface{}) *Selector {
+	t.Helper()
+	return e.must(t, s, emptyRec)
+}
+
+// MustNot checks if selector(s) is not present in the game.
+// If it's present it'll fail the test
+// s can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (e *Ebitest) MustNot(t *testing.T, s interface{}) {
+	t.Helper()
+	e.mustNot(t, s, emptyRec)
+}
+
+// GetAll returns all the repeated instances of s or none if nothing is found.
+// By default they are sorted by column and can overlap, which can be changed
+// with the opts OrderBy, NonOverlapping and Limit
+func (e *Ebitest) GetAll(s interface{}, opts ...getAllOptionsFn) []*Selector {
+	return e.getAll(s, emptyRec, opts...)
+}
+
+// Within returns a Scope in which all the searches are restricted to
+// the rect of the screen, like the Rec() of a Selector
+func (e *Ebitest) Within(rect image.Rectangle) *Scope {
+	return &Scope{
+		et:   e,
+		rect: rect,
+	}
+}
+
+// should is the implementation of Should searching only inside of rect.
+// If rect is empty it searches on all the screen
+func (e *Ebitest) should(t *testing.T, s interface{}, rect image.Rectangle) (*Selector, bool) {
 	t.Helper()
 	e.PingPong.Ping()
 	sc := e.game.GetScreen()
 
-	sel, ok := e.findSelector(sc, s)
+	sel, ok := e.findSelector(sc, s, rect)
 	if !ok {
-		msg := "selector not found"
-		if e.options.dumpErrorImages {
-			p := dumpErrorImages(sc, sel)
-			msg += "\nimage at: " + p
-		}
+		msg := e.notFoundMessage(sc, sel, rect)
 		assert.Fail(t, msg)
 		return nil, false
 	}
 
+	e.logf("%s found at %v", sel.label(), sel.rect)
 	return sel, true
 }
 
-// ShouldNot checks if selector(s) is not present in the game
-// s can be a: 'string', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
-func (e *Ebitest) ShouldNot(t *testing.T, s interface{}) bool {
+// shouldNot is the implementation of ShouldNot searching only inside of rect.
+// If rect is empty it searches on all the screen
+func (e *Ebitest) shouldNot(t *testing.T, s interface{}, rect image.Rectangle) bool {
 	t.Helper()
 	e.PingPong.Ping()
 	sc := e.game.GetScreen()
 
-	sel, ok := e.findSelector(sc, s)
+	sel, ok := e.findSelector(sc, s, rect)
 	if !ok {
 		return true
 	}
 
-	msg := "selector found"
-	if e.options.dumpErrorImages {
-		p := dumpErrorImages(sc, sel)
-		msg += "\nimage at: " + p
-	}
+	msg := e.foundMessage(sc, sel, rect)
 	assert.Fail(t, msg)
 	return false
 }
 
-// Must checks if selector(s) is present in the game and returns it.
-// If it's not present it'll fail the test
-// s can be a: 'string', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
-func (e *Ebitest) Must(t *testing.T, s interface{}) *Selector {
+// must is the implementation of Must searching only inside of rect.
+// If rect is empty it searches on all the screen
+func (e *Ebitest) must(t *testing.T, s interface{}, rect image.Rectangle) *Selector {
 	t.Helper()
 	e.PingPong.Ping()
 	sc := e.game.GetScreen()
 
-	sel, ok := e.findSelector(sc, s)
+	sel, ok := e.findSelector(sc, s, rect)
 	if !ok {
-		msg := "selector not found"
-		if e.options.dumpErrorImages {
-			p := dumpErrorImages(sc, sel)
-			msg += "\nimage at: " + p
-		}
+		msg := e.notFoundMessage(sc, sel, rect)
 		require.Fail(t, msg)
 		return nil
 	}
 
+	e.logf("%s found at %v", sel.label(), sel.rect)
 	return sel
 }
 
-// MustNot checks if selector(s) is not present in the game.
-// If it's present it'll fail the test
-// s can be a: 'string', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
-func (e *Ebitest) MustNot(t *testing.T, s interface{}) {
+// mustNot is the implementation of MustNot searching only inside of rect.
+// If rect is empty it searches on all the screen
+func (e *Ebitest) mustNot(t *testing.T, s interface{}, rect image.Rectangle) {
 	t.Helper()
 	e.PingPong.Ping()
 	sc := e.game.GetScreen()
 
-	sel, ok := e.findSelector(sc, s)
+	sel, ok := e.findSelector(sc, s, rect)
 	if !ok {
 		return
 	}
 
-	msg := "selector found"
-	if e.options.dumpErrorImages {
-		p := dumpErrorImages(sc, sel)
-		msg += "\nimage at: " + p
-	}
+	msg := e.foundMessage(sc, sel, rect)
 	require.Fail(t, msg)
 }
 
-// GetAll returns all the repeated instances of s or none if nothing is found
-func (e *Ebitest) GetAll(s interface{}) []*Selector {
+// getAll is the implementation of GetAll searching only inside of rect.
+// If rect is empty it searches on all the screen
+func (e *Ebitest) getAll(s interface{}, rect image.Rectangle, opts ...getAllOptionsFn) []*Selector {
 	sc := e.game.GetScreen()
-	sels, _ := e.findSelectors(sc, s, findAllSelectors)
+	sels, _ := e.findSelectors(sc, s, findAllSelectors, rect)
+
+	return applyGetAllOptions(sels, opts...)
+}
 
-	return sels
+// logf logs the message if there is a logger
+func (e *Ebitest) logf(format string, v ...interface{}) {
+	if e.options.logger != nil {
+		e.options.logger.Printf(format, v...)
+	}
 }
 
 // KeyTap taps all the keys at once
@@ -214,89 +403,236 @@ func (e *Ebitest) KeyTap(keys ...ebiten.Key) {
 func (e *Ebitest) getSelector(s interface{}) *Selector {
 	switch v := s.(type) {
 	case string:
-		return NewFromText(v, e.options.face, e.options.color)
+		return e.textSelector(Text(v))
+	case *TextSelector:
+		return e.textSelector(v)
 	case *ebiten.Image:
 		return NewFromImage(ebitenImageToImage(v))
 	case image.Image:
 		return NewFromImage(v)
 	case *Selector:
-		return NewFromImage(v.Image())
+		return v.base()
 	default:
-		panic(fmt.Sprintf("Invalid Selector of type %T, the supported ones are: 'string', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'", s))
+		panic(fmt.Sprintf("Invalid Selector of type %T, the supported ones are: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'", s))
 	}
 }
 
-// findSelector returns a Selector from ss if found. `all` will basically mean it'll return all of them
-func (e *Ebitest) findSelector(sc image.Image, ss interface{}) (*Selector, bool) {
-	sels, sel := e.findSelectors(sc, ss, !findAllSelectors)
+// findSelector returns a Selector from ss if found inside of rect
+func (e *Ebitest) findSelector(sc image.Image, ss interface{}, rect image.Rectangle) (*Selector, bool) {
+	sels, sel := e.findSelectors(sc, ss, !findAllSelectors, rect)
 	if len(sels) == 0 {
 		return sel, false
 	}
 	return sels[0], true
 }
 
-// findSelector returns a Selector from ss if found. `all` will basically mean it'll return all of them
-func (e *Ebitest) findSelectors(sc image.Image, ss interface{}, all bool) ([]*Selector, *Selector) {
+// findSelector returns a Selector from ss if found inside of rect. `all` will basically mean it'll return all of them.
+// The returned selectors have the position relative to the screen
+func (e *Ebitest) findSelectors(sc image.Image, ss interface{}, all bool, rect image.Rectangle) ([]*Selector, *Selector) {
 	selectors := make([]*Selector, 0)
 	bsel := e.getSelector(ss)
 
-	sx := sc.Bounds().Dx()
-	sy := sc.Bounds().Dy()
-	for x := range sx {
-		for y := range sy {
-			if hasImageAt(sc, bsel.Image(), x, y) {
-				sel := NewFromImage(bsel.Image())
-				selx := sel.Image().Bounds().Dx()
-				sely := sel.Image().Bounds().Dy()
-
-				sel.rect = image.Rect(x, y, x+selx, y+sely)
-				sel.PingPong = e.PingPong
-				selectors = append(selectors, sel)
-				if !all {
-					return selectors, bsel
-				}
+	region := screenRegion(sc, rect)
+	if bsel.finder != nil {
+		return bsel.finder.find(e, bsel, region, all)
+	}
+
+	so := searchOptions{
+		ct:        e.colorTolerance(bsel),
+		threshold: e.matchThreshold(bsel),
+		all:       all,
+		workers:   e.searchWorkers(),
+	}
+	for _, v := range e.variants(bsel) {
+		matches := e.template(bsel, v).search(region, so)
+		found := len(selectors)
+		for _, m := range matches {
+			// The variants are different forms of the same Selector
+			// so if one was already found at the same place by a
+			// previous variant it's ignored
+			if overlapsAny(m.rect.Add(region.Bounds().Min), selectors[:found]) {
+				continue
 			}
+			sel := e.found(bsel, m.rect.Add(region.Bounds().Min), m.score)
+			sel.scale = v.scale
+			sel.transform = v.transform
+			if bsel.anyColor {
+				sel.color = m.color
+			}
+			selectors = append(selectors, sel)
+		}
+		if !all && len(selectors) != 0 {
+			break
 		}
 	}
 
 	return selectors, bsel
 }
 
-// hasImageAt checks if the image sub is image i at the ix, iy
-func hasImageAt(i, sub image.Image, ix, iy int) bool {
-	sx, sy := sub.Bounds().Dx(), sub.Bounds().Dy()
-	for x := range sx {
-		for y := range sy {
-			sc := sub.At(x, y)
-			ic := i.At(ix+x, iy+y)
+// colorTolerance returns the ColorTolerance to use for sel, the one
+// of the Selector has priority over the default one
+func (e *Ebitest) colorTolerance(sel *Selector) ColorTolerance {
+	if sel.colorTolerance != nil {
+		return *sel.colorTolerance
+	}
+	return e.options.colorTolerance
+}
 
-			nsc := sc.(color.NRGBA)
-			// If the source it's transparent we ignore it
-			// we want to only compare colors so we consider
-			// it as good
-			if nsc.A != 255 {
-				continue
-			}
+// matchThreshold returns the minimum score to use for sel, the one
+// of the Selector has priority over the default one
+func (e *Ebitest) matchThreshold(sel *Selector) float64 {
+	if sel.matchThreshold != nil {
+		return min(1, max(0, *sel.matchThreshold))
+	}
+	if e.options.matchThreshold != nil {
+		return min(1, max(0, *e.options.matchThreshold))
+	}
+	if e.matcher(sel) == MatchSSIM {
+		return defaultSSIMThreshold
+	}
+	return defaultMatchThreshold
+}
 
-			if !equalColors(sc, ic) {
-				return false
-			}
+// matcher returns the Matcher to use for sel, the one
+// of the Selector has priority over the default one
+func (e *Ebitest) matcher(sel *Selector) Matcher {
+	if sel.matcher != nil {
+		return *sel.matcher
+	}
+	return e.options.matcher
+}
+
+// alphaThreshold returns the minimum alpha of the pixels to compare for sel,
+// the one of the Selector has priority over the default one
+func (e *Ebitest) alphaThreshold(sel *Selector) uint8 {
+	if sel.alphaThreshold != 0 {
+		return sel.alphaThreshold
+	}
+	if e.options.alphaThreshold != 0 {
+		return e.options.alphaThreshold
+	}
+	return defaultAlphaThreshold
+}
+
+// searchWorkers returns the number of goroutines to use when searching
+func (e *Ebitest) searchWorkers() int {
+	if e.options.searchWorkers > 0 {
+		return e.options.searchWorkers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// found returns a copy of bsel found on the screen at rect with the score
+func (e *Ebitest) found(bsel *Selector, rect image.Rectangle, score float64) *Selector {
+	sel := bsel.base()
+	sel.rect = rect
+	sel.score = score
+	sel.PingPong = e.PingPong
+	sel.et = e
+	return sel
+}
+
+// overlapsAny checks if rect overlaps with any of the sels
+func overlapsAny(rect image.Rectangle, sels []*Selector) bool {
+	for _, s := range sels {
+		if rect.Overlaps(s.Rec()) {
+			return true
+		}
+	}
+	return false
+}
+
+// template returns the template to search the variant v of sel
+func (e *Ebitest) template(sel *Selector, v variant) *template {
+	if e.matcher(sel) == MatchSSIM {
+		return newSSIMTemplate(v.img, e.alphaThreshold(sel))
+	}
+	if sel.anyColor {
+		return newShapeTemplate(v.img, e.alphaThreshold(sel))
+	}
+	return newTemplate(v.img, e.alphaThreshold(sel))
+}
+
+// screenRegion returns the part of sc inside of rect,
+// if rect is empty it returns all the sc
+func screenRegion(sc image.Image, rect image.Rectangle) *image.NRGBA {
+	img := toNRGBA(sc)
+	if rect == emptyRec {
+		return img
+	}
+	return img.SubImage(rect.Intersect(img.Bounds())).(*image.NRGBA)
+}
+
+// nearMiss sets on sel the position and score of the location of sc inside
+// of rect that is the closest to match it, if any is above minNearMissScore.
+// With MatchSSIM or AnyColor all the locations have to be checked, which is
+// slow, so it's only done if the failures are dumped or logged
+func (e *Ebitest) nearMiss(sc image.Image, sel *Selector, rect image.Rectangle) {
+	if (e.matcher(sel) == MatchSSIM || sel.anyColor) && !e.options.dumpErrorImages && e.options.logger == nil {
+		return
+	}
+	region := screenRegion(sc, rect)
+	minScore := minNearMissScore
+	for _, v := range e.variants(sel) {
+		if m, ok := e.template(sel, v).best(region, e.colorTolerance(sel), minScore, e.searchWorkers()); ok {
+			sel.rect = m.rect.Add(region.Bounds().Min)
+			sel.score = m.score
+			sel.scale = v.scale
+			sel.transform = v.transform
+			minScore = m.score
+		}
+	}
+}
+
+// notFoundMessage returns the failure message for when sel is not found on sc
+// inside of rect with the closest match to it if any
+func (e *Ebitest) notFoundMessage(sc image.Image, sel *Selector, rect image.Rectangle) string {
+	if sel.finder == nil {
+		e.nearMiss(sc, sel, rect)
+	}
+
+	msg := sel.label() + " not found"
+	if rect != emptyRec {
+		msg += fmt.Sprintf(" within %v", rect)
+	}
+	if d, ok := sel.finder.(describer); ok {
+		msg += " " + d.describe()
+	}
+	if sel.score != 0 {
+		msg += fmt.Sprintf("\nbest match: score %.3f at %v", sel.score, sel.rect)
+		if sel.scale != 1 {
+			msg += fmt.Sprintf(" with scale %v", sel.scale)
+		}
+		if !sel.transform.isIdentity() {
+			msg += fmt.Sprintf(" %v", sel.transform)
 		}
 	}
-	return true
+	if e.options.dumpErrorImages {
+		p := dumpErrorImages(sc, sel, rect, e.colorTolerance(sel), e.alphaThreshold(sel))
+		msg += "\nimage at: " + p
+	}
+	return msg
 }
 
-// equalColors checks if c1 and c2 have the same RGB
-func equalColors(c1, c2 color.Color) bool {
-	r1, g1, b1, _ := c1.RGBA()
-	r2, g2, b2, _ := c2.RGBA()
-	return r1 == r2 && g1 == g2 && b1 == b2
+// foundMessage returns the failure message for when sel is found on sc inside of rect
+func (e *Ebitest) foundMessage(sc image.Image, sel *Selector, rect image.Rectangle) string {
+	msg := sel.label() + " found"
+	if rect != emptyRec {
+		msg += fmt.Sprintf(" within %v", rect)
+	}
+	if e.options.dumpErrorImages {
+		p := dumpErrorImages(sc, sel, rect, e.colorTolerance(sel), e.alphaThreshold(sel))
+		msg += "\nimage at: " + p
+	}
+	return msg
 }
 
 // dumpErrorImages dumps a composition of the 2 images into 1 so it displays
-// what was checked
-func dumpErrorImages(s image.Image, sel *Selector) string {
-	i := sel.Image()
+// what was checked and where it was searched if rect is not empty. The pixels
+// of sel with at least an alpha of alphaThreshold that do not match s with the
+// ColorTolerance ct are highlighted
+func dumpErrorImages(s image.Image, sel *Selector, rect image.Rectangle, ct ColorTolerance, alphaThreshold uint8) string {
+	i := sel.dumpImage(s, ct, alphaThreshold)
 	sb := s.Bounds()
 	ib := i.Bounds()
 	x := sb.Dx() + ib.Dx()
@@ -306,23 +642,49 @@ func dumpErrorImages(s image.Image, sel *Selector) string {
 	draw.Draw(img, sb, s, image.Point{}, draw.Over)
 	draw.Draw(img, image.Rect(sb.Dx(), 0, x, ib.Dy()), i, image.Point{}, draw.Over)
 
+	if rect != emptyRec {
+		drawRectangle(img, rect, 1, scopeRectColor)
+	}
+	if d, ok := sel.finder.(rectsDumper); ok {
+		for _, r := range d.dumpRects() {
+			drawRectangle(img, r, 1, foundRectColor)
+		}
+	}
 	if sel.Rec() != emptyRec {
-		drawRectangle(img, sel.Rec(), 2)
+		drawRectangle(img, sel.Rec(), 2, selectorRectColor)
 	}
 
-	u, _ := uuid.NewV7()
-
-	ip := filepath.Join(baseDumpFoler, u.String()+".png")
+	ip := filepath.Join(baseDumpFoler, dumpFileName(sel.name))
 	writeImage(ip, img)
 
 	wd, _ := os.Getwd()
 	return filepath.Join(wd, ip)
 }
 
-// drawRectangle will draw in the image(img) the rectangel(rec) with thiknes
-func drawRectangle(img *image.RGBA, rec image.Rectangle, thickness int) {
-	col := color.RGBA{255, 0, 0, 255}
+// dumpFileName returns an unique name for a dump of the Selector named n,
+// which starts with the name of the file (if any) so it's easy to identify
+func dumpFileName(n string) string {
+	u, _ := uuid.NewV7()
+	if n == "" {
+		return u.String() + ".png"
+	}
+
+	base := filepath.Base(n)
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	base = strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, base)
+	return base + "-" + u.String() + ".png"
+}
 
+// drawRectangle will draw in the image(img) the rectangel(rec) with thiknes and color col
+func drawRectangle(img *image.RGBA, rec image.Rectangle, thickness int, col color.Color) {
 	for t := 0; t < thickness; t++ {
 		// draw horizontal lines
 		for x := rec.Min.X; x <= rec.Max.X; x++ {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-vgo/robotgo"
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/xescugc/ebitest"
 	"github.com/xescugc/ebitest/testdata"
@@ -35,6 +36,10 @@ func TestGameButton(t *testing.T) {
 	t1s := et.Must(t, text1)
 	t1_2s, _ := et.Should(t, text1_2)
 
+	t1s.Must(t, text1)
+	et.Within(t1s.Rec()).ShouldNot(t, text1_2)
+	et.Should(t, ebitest.Text(text1, ebitest.Face(face), ebitest.Color(color.White)))
+
 	// Fails
 	et.ShouldNot(t, text1_2)
 	et.ShouldNot(t, text2)
@@ -42,6 +47,7 @@ func TestGameButton(t *testing.T) {
 	// Fails
 	et.Should(t, text2)
 
+	assert.True(t, t1s.Exists())
 	t1s.Click()
 
 	et.Should(t, text1_2)
@@ -51,8 +57,15 @@ func TestGameButton(t *testing.T) {
 
 	et.ShouldNot(t, text1)
 	et.ShouldNot(t, text1_2)
+	assert.False(t, t1s.Exists())
+	assert.False(t, t1s.Refresh())
 	assert.Len(t, et.GetAll(text2), 2)
 
+	et.Should(t, ebitest.NewFromTextSubPixels(testdata.SubPixelText, face, color.White, 4))
+	et.Should(t, ebitest.NewFromTextLayout(testdata.MultiLineText, face, color.White, ebitest.TextLayout{
+		LayoutOptions: text.LayoutOptions{LineSpacing: testdata.MultiLineSpacing},
+	}))
+
 	et.KeyTap(ebiten.KeyI, ebiten.KeyShift)
 	assert.True(t, g.ClickedShiftI)
 }
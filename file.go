@@ -0,0 +1,181 @@
+package ebitest
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+const (
+	// maxDecodedFiles is the maximum number of decoded
+	// files that are cached, the least used are evicted
+	maxDecodedFiles = 256
+)
+
+// decodedFiles is the cache of the decoded files
+var decodedFiles = newFileCache(maxDecodedFiles)
+
+// fileKey identifies a file on a fs.FS, by its identity
+// on fsys, or on the OS if fsys is nil
+type fileKey struct {
+	fsys interface{}
+	path string
+}
+
+// fsAddress identifies the fs.FS that are pointers or maps by their address
+type fsAddress struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// fileCache is a cache of the decoded files that
+// keeps the max most recently used ones
+type fileCache struct {
+	mx  sync.Mutex
+	max int
+
+	imgs map[fileKey]image.Image
+
+	// keys are the keys of imgs from the least to the most used
+	keys []fileKey
+}
+
+// newFileCache returns a fileCache of n files
+func newFileCache(n int) *fileCache {
+	return &fileCache{
+		max:  n,
+		imgs: make(map[fileKey]image.Image),
+	}
+}
+
+// get returns the image of k if it's on the cache
+func (fc *fileCache) get(k fileKey) (image.Image, bool) {
+	fc.mx.Lock()
+	defer fc.mx.Unlock()
+
+	img, ok := fc.imgs[k]
+	if ok {
+		fc.touch(k)
+	}
+	return img, ok
+}
+
+// put adds the img of k to the cache evicting the least used one if it's full
+func (fc *fileCache) put(k fileKey, img image.Image) {
+	fc.mx.Lock()
+	defer fc.mx.Unlock()
+
+	if _, ok := fc.imgs[k]; ok {
+		fc.imgs[k] = img
+		fc.touch(k)
+		return
+	}
+	if len(fc.keys) >= fc.max {
+		delete(fc.imgs, fc.keys[0])
+		fc.keys = fc.keys[1:]
+	}
+	fc.imgs[k] = img
+	fc.keys = append(fc.keys, k)
+}
+
+// touch moves k to the most used
+func (fc *fileCache) touch(k fileKey) {
+	i := slices.Index(fc.keys, k)
+	fc.keys = append(slices.Delete(fc.keys, i, i+1), k)
+}
+
+// FromFile creates a new Selector from the image (PNG, JPEG or GIF) at path. The
+// decoded images are cached so it can be called many times for the same file and
+// the path is the name of the Selector used on the failure messages and dumps
+func FromFile(path string) (*Selector, error) {
+	key := path
+	if abs, err := filepath.Abs(path); err == nil {
+		key = abs
+	}
+	return fromFile(fileKey{path: key}, true, path, func() (io.ReadCloser, error) {
+		return os.Open(path)
+	})
+}
+
+// FromFS creates a new Selector from the image (PNG, JPEG or GIF) at path of fsys,
+// like an embed.FS with the assets of the game. It's cached and named as on FromFile
+func FromFS(fsys fs.FS, path string) (*Selector, error) {
+	id, cache := fsIdentity(fsys)
+	key := fileKey{
+		fsys: id,
+		path: path,
+	}
+	return fromFile(key, cache, path, func() (io.ReadCloser, error) {
+		return fsys.Open(path)
+	})
+}
+
+// fsIdentity returns the value that identifies fsys on the cache, which is
+// the address of the pointers and maps (like fstest.MapFS) or the value of the
+// ones that can be compared (like os.DirFS or embed.FS). If it can not be
+// identified it returns false and the files of it are not cached
+func fsIdentity(fsys fs.FS) (interface{}, bool) {
+	v := reflect.ValueOf(fsys)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil, false
+	case reflect.Pointer, reflect.Map:
+		return fsAddress{typ: v.Type(), ptr: v.Pointer()}, true
+	}
+	if !v.Comparable() {
+		return nil, false
+	}
+	return fsys, true
+}
+
+// fromFile returns a Selector named path from the image of open,
+// which is cached by key if cache is set
+func fromFile(key fileKey, cache bool, path string, open func() (io.ReadCloser, error)) (*Selector, error) {
+	if !cache {
+		return decodeFile(path, open)
+	}
+
+	if img, ok := decodedFiles.get(key); ok {
+		return newNamedSelector(img, path), nil
+	}
+
+	sel, err := decodeFile(path, open)
+	if err != nil {
+		return nil, err
+	}
+	decodedFiles.put(key, sel.img)
+	return sel, nil
+}
+
+// decodeFile returns a Selector named path from the image of open
+func decodeFile(path string, open func() (io.ReadCloser, error)) (*Selector, error) {
+
+	f, err := open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %q: %w", path, err)
+	}
+
+	return newNamedSelector(img, path), nil
+}
+
+// newNamedSelector creates a new Selector from img with the name n
+func newNamedSelector(img image.Image, n string) *Selector {
+	sel := NewFromImage(img)
+	sel.name = n
+	return sel
+}
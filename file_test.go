@@ -0,0 +1,156 @@
+package ebitest
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dirFS is a fs.FS of the files of dir that is not a pointer
+type dirFS struct {
+	dir string
+}
+
+func (d dirFS) Open(name string) (fs.File, error) {
+	return os.DirFS(d.dir).Open(name)
+}
+
+func TestFSIdentity(t *testing.T) {
+	mfs := fstest.MapFS{}
+	id, ok := fsIdentity(mfs)
+	require.True(t, ok)
+	mfs["a.png"] = &fstest.MapFile{}
+	id2, _ := fsIdentity(mfs)
+	assert.Equal(t, id, id2)
+
+	id, ok = fsIdentity(dirFS{dir: "a"})
+	require.True(t, ok)
+	assert.Equal(t, dirFS{dir: "a"}, id)
+
+	type wrapFS struct{ fs.FS }
+	_, ok = fsIdentity(wrapFS{mfs})
+	assert.False(t, ok)
+	_, ok = fsIdentity(nil)
+	assert.False(t, ok)
+}
+
+func TestFromFile(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.SetNRGBA(1, 1, color.NRGBA{255, 0, 0, 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "button.png"), buf.Bytes(), 0666))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.png"), []byte("not an image"), 0666))
+
+	t.Run("File", func(t *testing.T) {
+		s, err := FromFile(filepath.Join(dir, "button.png"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "button.png"), s.Name())
+		assert.Equal(t, img, s.Image())
+
+		// The second time it's from the cache
+		s2, err := FromFile(filepath.Join(dir, "button.png"))
+		require.NoError(t, err)
+		assert.Same(t, s.img, s2.img)
+		assert.NotSame(t, s, s2)
+	})
+	t.Run("FS", func(t *testing.T) {
+		s, err := FromFS(os.DirFS(dir), "button.png")
+		require.NoError(t, err)
+		assert.Equal(t, "button.png", s.Name())
+		assert.Equal(t, img, s.Image())
+
+		mfs := fstest.MapFS{"a/button.png": {Data: buf.Bytes()}}
+		s, err = FromFS(mfs, "a/button.png")
+		require.NoError(t, err)
+		assert.Equal(t, img, s.Image())
+		// The maps are identified by their address even if the content changes
+		mfs["b/other.png"] = &fstest.MapFile{Data: buf.Bytes()}
+		s2, err := FromFS(mfs, "a/button.png")
+		require.NoError(t, err)
+		assert.Same(t, s.img, s2.img)
+		s3, err := FromFS(fstest.MapFS{"a/button.png": {Data: buf.Bytes()}}, "a/button.png")
+		require.NoError(t, err)
+		assert.NotSame(t, s.img, s3.img)
+
+		// The ones that are not pointers are identified by their value
+		s, err = FromFS(dirFS{dir: dir}, "button.png")
+		require.NoError(t, err)
+		assert.Equal(t, img, s.Image())
+		s2, err = FromFS(dirFS{dir: dir}, "button.png")
+		require.NoError(t, err)
+		assert.Same(t, s.img, s2.img)
+
+		// The fs.FS that can not be compared are not cached
+		type wrapFS struct{ fs.FS }
+		s, err = FromFS(wrapFS{mfs}, "a/button.png")
+		require.NoError(t, err)
+		assert.Equal(t, img, s.Image())
+		s2, err = FromFS(wrapFS{mfs}, "a/button.png")
+		require.NoError(t, err)
+		assert.NotSame(t, s.img, s2.img)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		_, err := FromFile(filepath.Join(dir, "missing.png"))
+		assert.ErrorContains(t, err, "failed to open")
+
+		_, err = FromFS(os.DirFS(dir), "broken.png")
+		assert.ErrorContains(t, err, `failed to decode "broken.png"`)
+	})
+}
+
+func TestFileCache(t *testing.T) {
+	fc := newFileCache(2)
+	imgs := make([]image.Image, 3)
+	for i := range imgs {
+		imgs[i] = image.NewNRGBA(image.Rect(0, 0, i+1, 1))
+	}
+	k := func(i int) fileKey {
+		return fileKey{path: strconv.Itoa(i)}
+	}
+
+	fc.put(k(0), imgs[0])
+	fc.put(k(1), imgs[1])
+	img, ok := fc.get(k(0))
+	require.True(t, ok)
+	assert.Same(t, imgs[0], img)
+
+	// The least used is evicted when it's full
+	fc.put(k(2), imgs[2])
+	_, ok = fc.get(k(1))
+	assert.False(t, ok)
+	_, ok = fc.get(k(0))
+	assert.True(t, ok)
+	_, ok = fc.get(k(2))
+	assert.True(t, ok)
+	assert.Len(t, fc.imgs, 2)
+	assert.Equal(t, []fileKey{k(0), k(2)}, fc.keys)
+
+	// Updating one does not evict any
+	fc.put(k(0), imgs[1])
+	img, _ = fc.get(k(0))
+	assert.Same(t, imgs[1], img)
+	assert.Len(t, fc.imgs, 2)
+}
+
+func TestDumpFileName(t *testing.T) {
+	assert.True(t, strings.HasSuffix(dumpFileName(""), ".png"))
+	n := dumpFileName("assets/ui/play button.png")
+	assert.True(t, strings.HasPrefix(n, "play_button-"), n)
+	assert.NotEqual(t, n, dumpFileName("assets/ui/play button.png"))
+	n = dumpFileName("main-menu.play")
+	assert.True(t, strings.HasPrefix(n, "main-menu_play-"), n)
+}
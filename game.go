@@ -13,6 +13,9 @@ type Game struct {
 	mxScreen sync.RWMutex
 	screen   image.Image
 
+	mxLayoutScale sync.RWMutex
+	layoutScale   float64
+
 	game ebiten.Game
 	ctx  context.Context
 
@@ -48,8 +51,43 @@ func (g *Game) SetScreen(s image.Image) {
 	g.screen = s
 }
 
+// GetLayoutScale returns the scale at which the assets taken from
+// screenshots of the window are on the screen, see layoutScale
+func (g *Game) GetLayoutScale() float64 {
+	g.mxLayoutScale.RLock()
+	defer g.mxLayoutScale.RUnlock()
+
+	return g.layoutScale
+}
+
 func (g *Game) Layout(outsideWidth int, outsideHeight int) (int, int) {
-	return g.game.Layout(outsideWidth, outsideHeight)
+	w, h := g.game.Layout(outsideWidth, outsideHeight)
+
+	dsf := 1.0
+	if m := ebiten.Monitor(); m != nil {
+		dsf = m.DeviceScaleFactor()
+	}
+	if ls := layoutScale(w, outsideWidth, dsf); ls != 0 {
+		g.mxLayoutScale.Lock()
+		g.layoutScale = ls
+		g.mxLayoutScale.Unlock()
+	}
+
+	return w, h
+}
+
+// layoutScale returns the scale between the logical width w returned by the
+// game Layout, which is the size of the captured screen, and the width of the
+// window in device pixels (outsideWidth*dsf) which is the size of the assets
+// taken from screenshots of the window. It's 0 if it can not be calculated
+func layoutScale(w, outsideWidth int, dsf float64) float64 {
+	if w <= 0 || outsideWidth <= 0 {
+		return 0
+	}
+	if dsf <= 0 {
+		dsf = 1
+	}
+	return float64(w) / (float64(outsideWidth) * dsf)
 }
 
 // Update implements Game.
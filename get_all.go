@@ -0,0 +1,100 @@
+package ebitest
+
+import (
+	"sort"
+)
+
+// Order is the order in which GetAll returns the selectors
+type Order int
+
+const (
+	// OrderColumns sorts by column (left-to-right) and
+	// then by row (top-to-bottom), it's the default one
+	OrderColumns Order = iota
+
+	// OrderReading sorts by row (top-to-bottom) and then
+	// by column (left-to-right) as when reading
+	OrderReading
+
+	// OrderScore sorts by the highest score first
+	OrderScore
+)
+
+type getAllOptions struct {
+	order          Order
+	nonOverlapping bool
+	limit          int
+}
+
+type getAllOptionsFn func(*getAllOptions)
+
+// OrderBy sorts the selectors returned by GetAll with the Order o
+func OrderBy(o Order) getAllOptionsFn {
+	return func(gao *getAllOptions) {
+		gao.order = o
+	}
+}
+
+// NonOverlapping removes from GetAll the selectors that overlap with any
+// previous one on the Order, so only the first of them is returned
+func NonOverlapping() getAllOptionsFn {
+	return func(gao *getAllOptions) {
+		gao.nonOverlapping = true
+	}
+}
+
+// Limit returns at most n selectors from GetAll
+func Limit(n int) getAllOptionsFn {
+	return func(gao *getAllOptions) {
+		gao.limit = n
+	}
+}
+
+// applyGetAllOptions sorts, filters and limits the sels with the opts
+func applyGetAllOptions(sels []*Selector, opts ...getAllOptionsFn) []*Selector {
+	gao := getAllOptions{}
+	for _, ofn := range opts {
+		ofn(&gao)
+	}
+
+	sort.SliceStable(sels, func(i, j int) bool {
+		ri, rj := sels[i].Rec(), sels[j].Rec()
+		switch gao.order {
+		case OrderReading:
+			if ri.Min.Y != rj.Min.Y {
+				return ri.Min.Y < rj.Min.Y
+			}
+			return ri.Min.X < rj.Min.X
+		case OrderScore:
+			return sels[i].Score() > sels[j].Score()
+		default:
+			if ri.Min.X != rj.Min.X {
+				return ri.Min.X < rj.Min.X
+			}
+			return ri.Min.Y < rj.Min.Y
+		}
+	})
+
+	if gao.nonOverlapping {
+		kept := make([]*Selector, 0, len(sels))
+		for _, s := range sels {
+			overlaps := false
+			for _, k := range kept {
+				if s.Rec().Overlaps(k.Rec()) {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				kept = append(kept, s)
+			}
+		}
+		sels = kept
+	}
+
+	if gao.limit > 0 && len(sels) > gao.limit {
+		sels = sels[:gao.limit]
+	}
+
+	return sels
+}
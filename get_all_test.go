@@ -0,0 +1,58 @@
+package ebitest
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyGetAllOptions(t *testing.T) {
+	newSels := func() []*Selector {
+		return []*Selector{
+			{rect: image.Rect(0, 10, 10, 20), score: 0.8},
+			{rect: image.Rect(0, 12, 10, 22), score: 0.9},
+			{rect: image.Rect(20, 0, 30, 10), score: 1},
+			{rect: image.Rect(40, 10, 50, 20), score: 0.7},
+		}
+	}
+	rects := func(sels []*Selector) []image.Rectangle {
+		rs := make([]image.Rectangle, 0, len(sels))
+		for _, s := range sels {
+			rs = append(rs, s.Rec())
+		}
+		return rs
+	}
+
+	tcs := []struct {
+		name string
+		opts []getAllOptionsFn
+		exp  []image.Rectangle
+	}{
+		{
+			name: "Default",
+			exp:  []image.Rectangle{image.Rect(0, 10, 10, 20), image.Rect(0, 12, 10, 22), image.Rect(20, 0, 30, 10), image.Rect(40, 10, 50, 20)},
+		},
+		{
+			name: "OrderReading",
+			opts: []getAllOptionsFn{OrderBy(OrderReading)},
+			exp:  []image.Rectangle{image.Rect(20, 0, 30, 10), image.Rect(0, 10, 10, 20), image.Rect(40, 10, 50, 20), image.Rect(0, 12, 10, 22)},
+		},
+		{
+			name: "OrderScoreNonOverlapping",
+			opts: []getAllOptionsFn{OrderBy(OrderScore), NonOverlapping()},
+			exp:  []image.Rectangle{image.Rect(20, 0, 30, 10), image.Rect(0, 12, 10, 22), image.Rect(40, 10, 50, 20)},
+		},
+		{
+			name: "NonOverlappingLimit",
+			opts: []getAllOptionsFn{NonOverlapping(), Limit(2)},
+			exp:  []image.Rectangle{image.Rect(0, 10, 10, 20), image.Rect(20, 0, 30, 10)},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, rects(applyGetAllOptions(newSels(), tc.opts...)))
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package ebitest
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TextRecognizer recognizes the words on an image, it's used
+// by ShouldText and MustText. To use Tesseract build with
+// the tag 'ocr' and use NewTesseract
+type TextRecognizer interface {
+	Words(img image.Image) ([]Word, error)
+}
+
+// Word is a recognized word on an image
+type Word struct {
+	Text string
+	Rect image.Rectangle
+
+	// Confidence is how sure (0-1) the TextRecognizer is of the Text
+	Confidence float64
+
+	// Line identifies the line of the Word, the words with the
+	// same Line are sorted from left to right
+	Line int
+}
+
+var errNoTextRecognizer = errors.New("no TextRecognizer configured, use WithTextRecognizer")
+
+// WithTextRecognizer set's the TextRecognizer used by ShouldText and MustText
+func WithTextRecognizer(tr TextRecognizer) optionsFn {
+	return func(o *options) {
+		o.textRecognizer = tr
+	}
+}
+
+// ShouldText checks if the pattern is present on the game using the TextRecognizer
+// and returns a Selector with the position of the recognized words.
+// pattern can be a: 'string' or '*regexp.Regexp'
+func (e *Ebitest) ShouldText(t *testing.T, pattern interface{}) (*Selector, bool) {
+	t.Helper()
+	e.PingPong.Ping()
+	sc := e.game.GetScreen()
+
+	sel, err := e.findText(sc, pattern)
+	if err != nil {
+		assert.Fail(t, e.textNotFoundMessage(sc, pattern, err))
+		return nil, false
+	}
+
+	e.logf("text %q found at %v", patternString(pattern), sel.rect)
+	return sel, true
+}
+
+// MustText checks if the pattern is present on the game using the TextRecognizer
+// and returns a Selector with the position of the recognized words.
+// If it's not present it'll fail the test
+// pattern can be a: 'string' or '*regexp.Regexp'
+func (e *Ebitest) MustText(t *testing.T, pattern interface{}) *Selector {
+	t.Helper()
+	e.PingPong.Ping()
+	sc := e.game.GetScreen()
+
+	sel, err := e.findText(sc, pattern)
+	if err != nil {
+		require.Fail(t, e.textNotFoundMessage(sc, pattern, err))
+		return nil
+	}
+
+	e.logf("text %q found at %v", patternString(pattern), sel.rect)
+	return sel
+}
+
+// textNotFoundMessage returns the failure message for when pattern is not found on sc
+func (e *Ebitest) textNotFoundMessage(sc image.Image, pattern interface{}, err error) string {
+	msg := fmt.Sprintf("text %q not found: %s", patternString(pattern), err)
+	if e.options.dumpErrorImages {
+		p := dumpErrorImages(sc, NewFromImage(image.NewNRGBA(emptyRec)), emptyRec, ColorTolerance{}, defaultAlphaThreshold)
+		msg += "\nimage at: " + p
+	}
+	return msg
+}
+
+// findText returns a Selector of the first words of sc that match the pattern
+func (e *Ebitest) findText(sc image.Image, pattern interface{}) (*Selector, error) {
+	if e.options.textRecognizer == nil {
+		return nil, errNoTextRecognizer
+	}
+
+	re, err := textPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	words, err := e.options.textRecognizer.Words(sc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recognize the text: %w", err)
+	}
+
+	ws, ok := matchWords(words, re)
+	if !ok {
+		return nil, fmt.Errorf("the recognized text was %q", wordsText(words))
+	}
+
+	var (
+		rect image.Rectangle
+		conf float64
+	)
+	for _, w := range ws {
+		rect = rect.Union(w.Rect)
+		conf += w.Confidence
+	}
+
+	sel := NewFromImage(toNRGBA(sc).SubImage(rect))
+	sel.rect = rect
+	sel.score = conf / float64(len(ws))
+	sel.scale = 1
+	sel.PingPong = e.PingPong
+	sel.et = e
+
+	return sel, nil
+}
+
+// textPattern converts the pattern to a regexp, the strings
+// are matched literally
+func textPattern(pattern interface{}) (*regexp.Regexp, error) {
+	switch v := pattern.(type) {
+	case string:
+		return regexp.Compile(regexp.QuoteMeta(v))
+	case *regexp.Regexp:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("invalid pattern of type %T, the supported ones are: 'string' and '*regexp.Regexp'", pattern)
+	}
+}
+
+// patternString returns the string representation of the pattern
+func patternString(pattern interface{}) string {
+	if s, ok := pattern.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(pattern)
+}
+
+// matchWords returns the first consecutive words of the same
+// line that match the re when joined with a space
+func matchWords(words []Word, re *regexp.Regexp) ([]Word, bool) {
+	for _, line := range wordLines(words) {
+		var (
+			sb     strings.Builder
+			starts = make([]int, 0, len(line))
+		)
+		for i, w := range line {
+			if i != 0 {
+				sb.WriteString(" ")
+			}
+			starts = append(starts, sb.Len())
+			sb.WriteString(w.Text)
+		}
+
+		loc := re.FindStringIndex(sb.String())
+		if loc == nil {
+			continue
+		}
+
+		ws := make([]Word, 0)
+		for i, w := range line {
+			end := starts[i] + len(w.Text)
+			if starts[i] < loc[1] && end > loc[0] {
+				ws = append(ws, w)
+			}
+		}
+		if len(ws) != 0 {
+			return ws, true
+		}
+	}
+
+	return nil, false
+}
+
+// wordLines groups the words by Line keeping the order
+func wordLines(words []Word) [][]Word {
+	lines := make([][]Word, 0)
+	idx := make(map[int]int)
+	for _, w := range words {
+		i, ok := idx[w.Line]
+		if !ok {
+			i = len(lines)
+			idx[w.Line] = i
+			lines = append(lines, nil)
+		}
+		lines[i] = append(lines[i], w)
+	}
+	return lines
+}
+
+// wordsText returns all the text of the words by lines
+func wordsText(words []Word) string {
+	lines := make([]string, 0)
+	for _, line := range wordLines(words) {
+		txt := make([]string, 0, len(line))
+		for _, w := range line {
+			txt = append(txt, w.Text)
+		}
+		lines = append(lines, strings.Join(txt, " "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,72 @@
+//go:build ocr
+
+package ebitest
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+
+	"github.com/otiai10/gosseract/v2"
+)
+
+// Tesseract is a TextRecognizer that uses Tesseract through gosseract,
+// it needs Tesseract installed and to build with the tag 'ocr'
+type Tesseract struct {
+	langs []string
+}
+
+// NewTesseract returns a new Tesseract that recognizes the langs,
+// if none is set the default of Tesseract is used
+func NewTesseract(langs ...string) *Tesseract {
+	return &Tesseract{
+		langs: langs,
+	}
+}
+
+// Words implements TextRecognizer
+func (ts *Tesseract) Words(img image.Image) ([]Word, error) {
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, img); err != nil {
+		return nil, err
+	}
+
+	client := gosseract.NewClient()
+	defer client.Close()
+
+	if len(ts.langs) != 0 {
+		if err := client.SetLanguage(ts.langs...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := client.SetImageFromBytes(buff.Bytes()); err != nil {
+		return nil, err
+	}
+
+	// The verbose ones are also of each word but with
+	// the block, paragraph and line numbers set
+	boxes, err := client.GetBoundingBoxesVerbose()
+	if err != nil {
+		return nil, err
+	}
+
+	// lines gives an unique number to each line as
+	// the LineNum is relative to the paragraph
+	lines := make(map[[3]int]int)
+	words := make([]Word, 0, len(boxes))
+	for _, b := range boxes {
+		lk := [3]int{b.BlockNum, b.ParNum, b.LineNum}
+		if _, ok := lines[lk]; !ok {
+			lines[lk] = len(lines)
+		}
+		words = append(words, Word{
+			Text:       b.Word,
+			Rect:       b.Box.Add(img.Bounds().Min),
+			Confidence: b.Confidence / 100,
+			Line:       lines[lk],
+		})
+	}
+
+	return words, nil
+}
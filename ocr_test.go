@@ -0,0 +1,138 @@
+package ebitest
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"log"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchWords(t *testing.T) {
+	words := []Word{
+		{Text: "New", Rect: image.Rect(10, 10, 40, 20), Line: 0},
+		{Text: "Game", Rect: image.Rect(45, 10, 90, 20), Line: 0},
+		{Text: "Load", Rect: image.Rect(10, 30, 40, 40), Line: 1},
+		{Text: "Save", Rect: image.Rect(45, 30, 80, 40), Line: 1},
+		{Text: "3", Rect: image.Rect(85, 30, 90, 40), Line: 1},
+	}
+
+	tcs := []struct {
+		name    string
+		pattern interface{}
+		exp     []string
+	}{
+		{name: "Word", pattern: "Load", exp: []string{"Load"}},
+		{name: "MultipleWords", pattern: "New Game", exp: []string{"New", "Game"}},
+		{name: "PartialWord", pattern: "ave", exp: []string{"Save"}},
+		{name: "Regexp", pattern: regexp.MustCompile(`Save \d+`), exp: []string{"Save", "3"}},
+		{name: "NotAcrossLines", pattern: "Game Load"},
+		{name: "Literal", pattern: "Save .", exp: nil},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			re, err := textPattern(tc.pattern)
+			require.NoError(t, err)
+
+			ws, ok := matchWords(words, re)
+			assert.Equal(t, tc.exp != nil, ok)
+
+			var txt []string
+			for _, w := range ws {
+				txt = append(txt, w.Text)
+			}
+			assert.Equal(t, tc.exp, txt)
+		})
+	}
+}
+
+// fakeRecognizer is a TextRecognizer that returns the words or the err
+type fakeRecognizer struct {
+	words []Word
+	err   error
+}
+
+func (fr fakeRecognizer) Words(img image.Image) ([]Word, error) {
+	return fr.words, fr.err
+}
+
+func TestFindText(t *testing.T) {
+	sc := newSolidImage(100, 50, testBackground)
+	words := []Word{
+		{Text: "New", Rect: image.Rect(10, 10, 40, 20), Confidence: 0.9, Line: 0},
+		{Text: "Game", Rect: image.Rect(45, 10, 90, 20), Confidence: 0.8, Line: 0},
+		{Text: "Load", Rect: image.Rect(10, 30, 40, 40), Confidence: 0.7, Line: 1},
+		{Text: "Save", Rect: image.Rect(45, 30, 80, 40), Confidence: 0.5, Line: 1},
+		{Text: "3", Rect: image.Rect(85, 28, 90, 42), Confidence: 0.6, Line: 1},
+	}
+
+	tcs := []struct {
+		name    string
+		tr      TextRecognizer
+		pattern interface{}
+		rect    image.Rectangle
+		score   float64
+		err     string
+	}{
+		{name: "Word", tr: fakeRecognizer{words: words}, pattern: "Load", rect: image.Rect(10, 30, 40, 40), score: 0.7},
+		{name: "MultipleWords", tr: fakeRecognizer{words: words}, pattern: regexp.MustCompile(`Save \d`), rect: image.Rect(45, 28, 90, 42), score: 0.55},
+		{name: "NotFound", tr: fakeRecognizer{words: words}, pattern: "Quit", err: `the recognized text was "New Game\nLoad Save 3"`},
+		{name: "NoTextRecognizer", pattern: "Load", err: errNoTextRecognizer.Error()},
+		{name: "InvalidPattern", tr: fakeRecognizer{words: words}, pattern: 3, err: "invalid pattern of type int, the supported ones are: 'string' and '*regexp.Regexp'"},
+		{name: "RecognizerError", tr: fakeRecognizer{err: errors.New("no tessdata")}, pattern: "Load", err: "failed to recognize the text: no tessdata"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			e, _ := newTestEbitest(t, sc, WithTextRecognizer(tc.tr))
+
+			sel, err := e.findText(sc, tc.pattern)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				assert.Nil(t, sel)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.rect, sel.Rec())
+				assert.InDelta(t, tc.score, sel.Score(), 0.0001)
+				assert.Equal(t, tc.rect.Size(), sel.Image().Bounds().Size())
+			}
+
+			ft := &testing.T{}
+			ssel, ok := e.ShouldText(ft, tc.pattern)
+			assert.Equal(t, tc.err == "", ok)
+			assert.Equal(t, tc.err != "", ft.Failed())
+			if ok {
+				assert.Equal(t, tc.rect, ssel.Rec())
+			}
+
+			var msel *Selector
+			assert.Equal(t, tc.err != "", mustFails(func(t *testing.T) { msel = e.MustText(t, tc.pattern) }))
+			if tc.err == "" {
+				assert.Equal(t, tc.rect, msel.Rec())
+			}
+		})
+	}
+
+	e, _ := newTestEbitest(t, sc)
+	assert.Equal(t, `text "Load" not found: `+errNoTextRecognizer.Error(), e.textNotFoundMessage(sc, "Load", errNoTextRecognizer))
+}
+
+func TestTextLogs(t *testing.T) {
+	var buf bytes.Buffer
+	words := []Word{{Text: "Load", Rect: image.Rect(10, 30, 40, 40), Confidence: 0.7}}
+	e, _ := newTestEbitest(t, newSolidImage(100, 50, testBackground), WithTextRecognizer(fakeRecognizer{words: words}), WithLogger(log.New(&buf, "", 0)))
+
+	e.ShouldText(t, "Load")
+	e.MustText(t, regexp.MustCompile(`L.ad`))
+	assert.Equal(t, "text \"Load\" found at (10,30)-(40,40)\ntext \"L.ad\" found at (10,30)-(40,40)\n", buf.String())
+
+	// The failures are not logged
+	buf.Reset()
+	e.ShouldText(&testing.T{}, "Quit")
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,234 @@
+package ebitest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// probeDumpSize is the size of the image of the expected
+	// color on the dumps of the pixel assertions
+	probeDumpSize = 16
+
+	// probePointPadding is the padding around a pixel
+	// so it can be seen when highlighted on the dumps
+	probePointPadding = 2
+)
+
+// ShouldColorAt checks if the pixel of the screen at x, y is the color c within the tolerance tol
+func (e *Ebitest) ShouldColorAt(t *testing.T, x, y int, c color.Color, tol ColorTolerance) bool {
+	t.Helper()
+	if msg, ok := e.colorAt(x, y, c, tol); !ok {
+		assert.Fail(t, msg)
+		return false
+	}
+	return true
+}
+
+// MustColorAt checks if the pixel of the screen at x, y is the color c within the tolerance tol.
+// If it's not it'll fail the test
+func (e *Ebitest) MustColorAt(t *testing.T, x, y int, c color.Color, tol ColorTolerance) {
+	t.Helper()
+	if msg, ok := e.colorAt(x, y, c, tol); !ok {
+		require.Fail(t, msg)
+	}
+}
+
+// ShouldRegionColor checks if the average color of the rect of the screen is the color c
+// within the tolerance tol. If rect is empty it's all the screen
+func (e *Ebitest) ShouldRegionColor(t *testing.T, rect image.Rectangle, c color.Color, tol ColorTolerance) bool {
+	t.Helper()
+	if msg, ok := e.regionColor(rect, c, tol); !ok {
+		assert.Fail(t, msg)
+		return false
+	}
+	return true
+}
+
+// MustRegionColor checks if the average color of the rect of the screen is the color c
+// within the tolerance tol. If rect is empty it's all the screen. If it's not it'll fail the test
+func (e *Ebitest) MustRegionColor(t *testing.T, rect image.Rectangle, c color.Color, tol ColorTolerance) {
+	t.Helper()
+	if msg, ok := e.regionColor(rect, c, tol); !ok {
+		require.Fail(t, msg)
+	}
+}
+
+// ShouldColorFraction checks if at least the fraction (0-1) of the pixels of the rect of the screen
+// are the color c within the tolerance tol, like a health bar that is at least half red.
+// If rect is empty it's all the screen
+func (e *Ebitest) ShouldColorFraction(t *testing.T, rect image.Rectangle, c color.Color, tol ColorTolerance, fraction float64) bool {
+	t.Helper()
+	if msg, ok := e.colorFraction(rect, c, tol, fraction); !ok {
+		assert.Fail(t, msg)
+		return false
+	}
+	return true
+}
+
+// MustColorFraction checks if at least the fraction (0-1) of the pixels of the rect of the screen
+// are the color c within the tolerance tol. If rect is empty it's all the screen.
+// If it's not it'll fail the test
+func (e *Ebitest) MustColorFraction(t *testing.T, rect image.Rectangle, c color.Color, tol ColorTolerance, fraction float64) {
+	t.Helper()
+	if msg, ok := e.colorFraction(rect, c, tol, fraction); !ok {
+		require.Fail(t, msg)
+	}
+}
+
+// Histogram returns how many pixels of each color has the rect
+// of the screen. If rect is empty it's all the screen
+func (e *Ebitest) Histogram(rect image.Rectangle) map[color.NRGBA]int {
+	e.PingPong.Ping()
+	return histogram(screenRegion(e.game.GetScreen(), rect))
+}
+
+// colorAt returns the failure message and false if the pixel at x, y
+// of the screen is not c within tol
+func (e *Ebitest) colorAt(x, y int, c color.Color, tol ColorTolerance) (string, bool) {
+	e.PingPong.Ping()
+	sc := e.game.GetScreen()
+	exp := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	p := image.Pt(x, y)
+	if !p.In(sc.Bounds()) {
+		return fmt.Sprintf("pixel %v outside of the screen %v", p, sc.Bounds()), false
+	}
+
+	got := color.NRGBAModel.Convert(sc.At(x, y)).(color.NRGBA)
+	if pixelEqualAlpha(got, exp, tol) {
+		return "", true
+	}
+
+	msg := fmt.Sprintf("color at %v is %s, expected %s", p, colorString(got), colorString(exp))
+	probe := image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))}.Inset(-probePointPadding)
+	return e.probeMessage(sc, msg, probe, exp, tol), false
+}
+
+// regionColor returns the failure message and false if the average
+// color of the rect of the screen is not c within tol
+func (e *Ebitest) regionColor(rect image.Rectangle, c color.Color, tol ColorTolerance) (string, bool) {
+	e.PingPong.Ping()
+	sc := e.game.GetScreen()
+	exp := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	region := screenRegion(sc, rect)
+	if region.Bounds().Empty() {
+		return fmt.Sprintf("region %v outside of the screen %v", rect, sc.Bounds()), false
+	}
+
+	got := averageColor(region)
+	if pixelEqualAlpha(got, exp, tol) {
+		return "", true
+	}
+
+	msg := fmt.Sprintf("average color of %v is %s, expected %s", region.Bounds(), colorString(got), colorString(exp))
+	return e.probeMessage(sc, msg, region.Bounds(), exp, tol), false
+}
+
+// colorFraction returns the failure message and false if less than the
+// fraction of pixels of the rect of the screen are c within tol
+func (e *Ebitest) colorFraction(rect image.Rectangle, c color.Color, tol ColorTolerance, fraction float64) (string, bool) {
+	e.PingPong.Ping()
+	sc := e.game.GetScreen()
+	exp := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	region := screenRegion(sc, rect)
+	if region.Bounds().Empty() {
+		return fmt.Sprintf("region %v outside of the screen %v", rect, sc.Bounds()), false
+	}
+
+	got := colorFraction(histogram(region), exp, tol)
+	if got >= fraction {
+		return "", true
+	}
+
+	msg := fmt.Sprintf("%.3f of the pixels of %v are %s, expected at least %.3f", got, region.Bounds(), colorString(exp), fraction)
+	return e.probeMessage(sc, msg, region.Bounds(), exp, tol), false
+}
+
+// probeMessage returns the msg with the dump of sc in which the probe
+// area is highlighted and the expected color (within tol) is the selector
+func (e *Ebitest) probeMessage(sc image.Image, msg string, probe image.Rectangle, exp color.NRGBA, tol ColorTolerance) string {
+	if !e.options.dumpErrorImages {
+		return msg
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, probeDumpSize, probeDumpSize))
+	for x := range probeDumpSize {
+		for y := range probeDumpSize {
+			img.SetNRGBA(x, y, color.NRGBA{exp.R, exp.G, exp.B, 255})
+		}
+	}
+	sel := NewFromImage(img)
+	sel.rect = probe
+
+	p := dumpErrorImages(sc, sel, emptyRec, tol, e.alphaThreshold(sel))
+	return msg + "\nimage at: " + p
+}
+
+// histogram returns how many pixels of each color has img
+func histogram(img *image.NRGBA) map[color.NRGBA]int {
+	h := make(map[color.NRGBA]int)
+	b := img.Bounds()
+	for x := range b.Dx() {
+		for y := range b.Dy() {
+			h[nrgbaAt(img, x, y)]++
+		}
+	}
+	return h
+}
+
+// colorFraction returns the fraction of the pixels of
+// the histogram h that are the color c within tol
+func colorFraction(h map[color.NRGBA]int, c color.NRGBA, tol ColorTolerance) float64 {
+	var total, matches int
+	for hc, n := range h {
+		total += n
+		if pixelEqualAlpha(hc, c, tol) {
+			matches += n
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(matches) / float64(total)
+}
+
+// averageColor returns the average RGBA of the pixels of img
+func averageColor(img *image.NRGBA) color.NRGBA {
+	var r, g, b, a, n uint64
+	bs := img.Bounds()
+	for x := range bs.Dx() {
+		for y := range bs.Dy() {
+			c := nrgbaAt(img, x, y)
+			r += uint64(c.R)
+			g += uint64(c.G)
+			b += uint64(c.B)
+			a += uint64(c.A)
+			n++
+		}
+	}
+	if n == 0 {
+		return color.NRGBA{}
+	}
+	return color.NRGBA{
+		R: uint8((r + n/2) / n),
+		G: uint8((g + n/2) / n),
+		B: uint8((b + n/2) / n),
+		A: uint8((a + n/2) / n),
+	}
+}
+
+// colorString returns c as #rrggbb or as #rrggbbaa if it's not opaque
+func colorString(c color.NRGBA) string {
+	if c.A != 255 {
+		return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
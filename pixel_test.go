@@ -0,0 +1,192 @@
+package ebitest
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPixelScreen returns a screen with a red square at 10,10 of 10x10
+// and the top-left pixel fully transparent
+func newPixelScreen() *image.NRGBA {
+	sc := newSolidImage(60, 40, testBackground)
+	fillRect(sc, image.Rect(10, 10, 20, 20), testRed)
+	sc.SetNRGBA(0, 0, color.NRGBA{})
+	return sc
+}
+
+// mustFails runs fn with a fake T and returns if it failed, as the
+// Must assertions stop the goroutine it runs on a new one
+func mustFails(fn func(t *testing.T)) bool {
+	ft := &testing.T{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(ft)
+	}()
+	<-done
+	return ft.Failed()
+}
+
+func TestShouldColorAt(t *testing.T) {
+	e, _ := newTestEbitest(t, newPixelScreen())
+
+	tcs := []struct {
+		name string
+		x, y int
+		c    color.Color
+		tol  ColorTolerance
+		msg  string
+	}{
+		{name: "Equal", x: 10, y: 10, c: testRed},
+		{name: "Tolerance", x: 10, y: 10, c: color.NRGBA{250, 5, 0, 255}, tol: ColorTolerance{Delta: 5}},
+		{name: "Different", x: 10, y: 10, c: testGreen, msg: "color at (10,10) is #ff0000, expected #00ff00"},
+		{name: "Transparent", x: 0, y: 0, c: testBackground, msg: "color at (0,0) is #00000000, expected #000000"},
+		{name: "Outside", x: 60, y: 10, c: testRed, msg: "pixel (60,10) outside of the screen (0,0)-(60,40)"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, ok := e.colorAt(tc.x, tc.y, tc.c, tc.tol)
+			assert.Equal(t, tc.msg, msg)
+			assert.Equal(t, tc.msg == "", ok)
+
+			ft := &testing.T{}
+			assert.Equal(t, ok, e.ShouldColorAt(ft, tc.x, tc.y, tc.c, tc.tol))
+			assert.Equal(t, !ok, ft.Failed())
+			assert.Equal(t, !ok, mustFails(func(t *testing.T) { e.MustColorAt(t, tc.x, tc.y, tc.c, tc.tol) }))
+		})
+	}
+}
+
+func TestShouldRegionColor(t *testing.T) {
+	e, _ := newTestEbitest(t, newPixelScreen())
+
+	tcs := []struct {
+		name string
+		rect image.Rectangle
+		c    color.Color
+		tol  ColorTolerance
+		msg  string
+	}{
+		{name: "Equal", rect: image.Rect(10, 10, 20, 20), c: testRed},
+		{name: "Average", rect: image.Rect(5, 10, 20, 20), c: color.NRGBA{170, 0, 0, 255}},
+		{name: "Different", rect: image.Rect(5, 10, 20, 20), c: testRed, tol: ColorTolerance{Delta: 80}, msg: "average color of (5,10)-(20,20) is #aa0000, expected #ff0000"},
+		{name: "Transparent", rect: image.Rect(0, 0, 1, 1), c: testBackground, msg: "average color of (0,0)-(1,1) is #00000000, expected #000000"},
+		{name: "Clipped", rect: image.Rect(10, 10, 20, 20).Add(image.Pt(0, -15)), c: testBackground},
+		{name: "Outside", rect: image.Rect(60, 0, 70, 10), c: testRed, msg: "region (60,0)-(70,10) outside of the screen (0,0)-(60,40)"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, ok := e.regionColor(tc.rect, tc.c, tc.tol)
+			assert.Equal(t, tc.msg, msg)
+			assert.Equal(t, tc.msg == "", ok)
+
+			ft := &testing.T{}
+			assert.Equal(t, ok, e.ShouldRegionColor(ft, tc.rect, tc.c, tc.tol))
+			assert.Equal(t, !ok, ft.Failed())
+			assert.Equal(t, !ok, mustFails(func(t *testing.T) { e.MustRegionColor(t, tc.rect, tc.c, tc.tol) }))
+		})
+	}
+}
+
+func TestShouldColorFraction(t *testing.T) {
+	e, _ := newTestEbitest(t, newPixelScreen())
+
+	tcs := []struct {
+		name     string
+		rect     image.Rectangle
+		c        color.Color
+		fraction float64
+		msg      string
+	}{
+		{name: "All", rect: image.Rect(10, 10, 20, 20), c: testRed, fraction: 1},
+		{name: "Enough", rect: image.Rect(5, 10, 20, 20), c: testRed, fraction: 0.6},
+		{name: "NotEnough", rect: image.Rect(5, 10, 20, 20), c: testRed, fraction: 0.7, msg: "0.667 of the pixels of (5,10)-(20,20) are #ff0000, expected at least 0.700"},
+		{name: "Transparent", rect: image.Rect(0, 0, 2, 1), c: testBackground, fraction: 1, msg: "0.500 of the pixels of (0,0)-(2,1) are #000000, expected at least 1.000"},
+		{name: "Screen", c: testRed, fraction: 100.0 / 2400},
+		{name: "Outside", rect: image.Rect(0, 40, 10, 50), c: testRed, fraction: 0.5, msg: "region (0,40)-(10,50) outside of the screen (0,0)-(60,40)"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, ok := e.colorFraction(tc.rect, tc.c, ColorTolerance{}, tc.fraction)
+			assert.Equal(t, tc.msg, msg)
+			assert.Equal(t, tc.msg == "", ok)
+
+			ft := &testing.T{}
+			assert.Equal(t, ok, e.ShouldColorFraction(ft, tc.rect, tc.c, ColorTolerance{}, tc.fraction))
+			assert.Equal(t, !ok, ft.Failed())
+			assert.Equal(t, !ok, mustFails(func(t *testing.T) { e.MustColorFraction(t, tc.rect, tc.c, ColorTolerance{}, tc.fraction) }))
+		})
+	}
+}
+
+func TestProbeMessage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(baseDumpFoler, 0777))
+	e, _ := newTestEbitest(t, newPixelScreen(), WithDumpErrorImages())
+
+	msg, ok := e.colorAt(10, 10, testGreen, ColorTolerance{})
+	require.False(t, ok)
+	m, p, found := strings.Cut(msg, "\nimage at: ")
+	require.True(t, found)
+	assert.Equal(t, "color at (10,10) is #ff0000, expected #00ff00", m)
+
+	f, err := os.Open(p)
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+
+	// The screen with the expected color on the right
+	require.Equal(t, image.Rect(0, 0, 60+probeDumpSize, 40), img.Bounds())
+	assert.True(t, equalColors(testGreen, img.At(60, 0), ColorTolerance{}))
+	assert.True(t, equalColors(testGreen, img.At(60+probeDumpSize-1, probeDumpSize-1), ColorTolerance{}))
+
+	// The probed pixel is highlighted with its padding and is not covered
+	assert.True(t, equalColors(selectorRectColor, img.At(8, 8), ColorTolerance{}))
+	assert.True(t, equalColors(selectorRectColor, img.At(13, 13), ColorTolerance{}))
+	assert.True(t, equalColors(testRed, img.At(10, 10), ColorTolerance{}))
+	assert.True(t, equalColors(testBackground, img.At(7, 7), ColorTolerance{}))
+
+	// Without the option there is no dump
+	e.options.dumpErrorImages = false
+	msg, _ = e.colorAt(10, 10, testGreen, ColorTolerance{})
+	assert.Equal(t, "color at (10,10) is #ff0000, expected #00ff00", msg)
+}
+
+func TestPixelHelpers(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	green := color.NRGBA{0, 255, 0, 255}
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := range 4 {
+		for y := range 2 {
+			c := red
+			if x == 3 {
+				c = green
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	h := histogram(img)
+	assert.Equal(t, map[color.NRGBA]int{red: 6, green: 2}, h)
+	assert.Equal(t, 0.75, colorFraction(h, red, ColorTolerance{}))
+	assert.Equal(t, 0.75, colorFraction(h, color.NRGBA{250, 5, 0, 255}, ColorTolerance{Delta: 5}))
+	assert.Equal(t, 0.0, colorFraction(nil, red, ColorTolerance{}))
+
+	assert.Equal(t, color.NRGBA{191, 64, 0, 255}, averageColor(img))
+	// On a part of the image
+	assert.Equal(t, green, averageColor(img.SubImage(image.Rect(3, 0, 4, 2)).(*image.NRGBA)))
+	assert.Equal(t, map[color.NRGBA]int{green: 2}, histogram(img.SubImage(image.Rect(3, 0, 4, 2)).(*image.NRGBA)))
+
+	assert.Equal(t, "#ff0000", colorString(red))
+}
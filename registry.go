@@ -0,0 +1,84 @@
+package ebitest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Define registers the selector(s) with the name n so it can be used with Named, and
+// returns it named so the failure messages, dumps and logs show the name. Defining
+// the same name again replaces it
+// s can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (e *Ebitest) Define(n string, s interface{}) *Selector {
+	sel := e.getSelector(s)
+	sel.name = n
+
+	e.mxRegistry.Lock()
+	defer e.mxRegistry.Unlock()
+	if e.registry == nil {
+		e.registry = make(map[string]*Selector)
+	}
+	e.registry[n] = sel
+
+	return sel.base()
+}
+
+// Named returns the Selector defined with the name n,
+// it panics if it was not defined
+func (e *Ebitest) Named(n string) *Selector {
+	e.mxRegistry.Lock()
+	defer e.mxRegistry.Unlock()
+
+	sel, ok := e.registry[n]
+	if !ok {
+		names := make([]string, 0, len(e.registry))
+		for rn := range e.registry {
+			names = append(names, rn)
+		}
+		sort.Strings(names)
+		panic(fmt.Sprintf("Selector %q not defined, the defined ones are: %s", n, strings.Join(names, ", ")))
+	}
+	return sel.base()
+}
+
+// DefinePage defines all the non nil '*ebitest.Selector' fields of the struct pointed by page
+// with the name prefix.field, where the field is the tag `ebitest:"name"` or the name of the
+// field (the ones with the tag "-" are skipped), and sets them to the named ones. So the
+// selectors of a screen can be grouped in a struct (page object) like
+//
+//	type MainMenu struct {
+//		Play *ebitest.Selector `ebitest:"play"`
+//		Quit *ebitest.Selector `ebitest:"quit"`
+//	}
+//
+// and et.DefinePage("main-menu", &menu) defines "main-menu.play" and "main-menu.quit"
+func (e *Ebitest) DefinePage(prefix string, page interface{}) {
+	v := reflect.ValueOf(page)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Invalid page of type %T, it has to be a pointer to a struct", page))
+	}
+	v = v.Elem()
+
+	selType := reflect.TypeOf(&Selector{})
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() || f.Type != selType || v.Field(i).IsNil() {
+			continue
+		}
+
+		n := f.Name
+		if tag, ok := f.Tag.Lookup("ebitest"); ok {
+			if tag == "-" {
+				continue
+			}
+			n = tag
+		}
+		if prefix != "" {
+			n = prefix + "." + n
+		}
+
+		v.Field(i).Set(reflect.ValueOf(e.Define(n, v.Field(i).Interface())))
+	}
+}
@@ -0,0 +1,43 @@
+package ebitest
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	e := &Ebitest{}
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+
+	sel := e.Define("main-menu.play", img)
+	assert.Equal(t, "main-menu.play", sel.Name())
+	assert.Equal(t, `selector "main-menu.play"`, sel.label())
+	assert.Equal(t, "main-menu.play", e.Named("main-menu.play").Name())
+	assert.PanicsWithValue(t, `Selector "quit" not defined, the defined ones are: main-menu.play`, func() { e.Named("quit") })
+
+	type page struct {
+		Play    *Selector `ebitest:"play"`
+		Quit    *Selector
+		Skipped *Selector `ebitest:"-"`
+		Missing *Selector
+		other   *Selector
+	}
+	p := &page{
+		Play:    NewFromImage(img),
+		Quit:    NewFromImage(img),
+		Skipped: NewFromImage(img),
+		other:   NewFromImage(img),
+	}
+	e.DefinePage("options", p)
+	require.NotNil(t, p.Play)
+	assert.Equal(t, "options.play", p.Play.Name())
+	assert.Equal(t, "options.Quit", p.Quit.Name())
+	assert.Equal(t, "", p.Skipped.Name())
+	assert.Nil(t, p.Missing)
+	assert.Equal(t, "options.Quit", e.Named("options.Quit").Name())
+
+	assert.Panics(t, func() { e.DefinePage("x", page{}) })
+}
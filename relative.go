@@ -0,0 +1,185 @@
+package ebitest
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// relation is where the target of a relative Selector has to be from the anchor
+type relation int
+
+const (
+	relationBelow relation = iota
+	relationAbove
+	relationRightOf
+	relationLeftOf
+	relationNear
+	relationSameRow
+)
+
+// relative finds the target that is on a relation with the anchor
+type relative struct {
+	relation    relation
+	anchor      interface{}
+	target      interface{}
+	maxDistance float64
+
+	// anchorRect is where the anchor was found on the
+	// last search, it's only used on the failure messages
+	anchorRect  image.Rectangle
+	anchorFound bool
+}
+
+// Below returns a Selector that finds the target that is below the anchor, the closest one first.
+// The anchor can be a found Selector (its Rec is used), an image.Rectangle or any selector that
+// is searched first. The target can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func Below(anchor, target interface{}) *Selector {
+	return newRelative(relationBelow, anchor, target, 0)
+}
+
+// Above returns a Selector that finds the target that is above the anchor, the closest one first.
+// The anchor and the target are as on Below
+func Above(anchor, target interface{}) *Selector {
+	return newRelative(relationAbove, anchor, target, 0)
+}
+
+// RightOf returns a Selector that finds the target that is at the right of the anchor, the closest one first.
+// The anchor and the target are as on Below
+func RightOf(anchor, target interface{}) *Selector {
+	return newRelative(relationRightOf, anchor, target, 0)
+}
+
+// LeftOf returns a Selector that finds the target that is at the left of the anchor, the closest one first.
+// The anchor and the target are as on Below
+func LeftOf(anchor, target interface{}) *Selector {
+	return newRelative(relationLeftOf, anchor, target, 0)
+}
+
+// Near returns a Selector that finds the target that is at most at maxDistance pixels from the anchor
+// in any direction, the closest one first. The anchor and the target are as on Below
+func Near(anchor, target interface{}, maxDistance float64) *Selector {
+	return newRelative(relationNear, anchor, target, maxDistance)
+}
+
+// SameRowAs returns a Selector that finds the target that shares at least one row of pixels with
+// the anchor, the closest one first. The anchor and the target are as on Below
+func SameRowAs(anchor, target interface{}) *Selector {
+	return newRelative(relationSameRow, anchor, target, 0)
+}
+
+// newRelative returns a Selector that finds target on the relation r with anchor
+func newRelative(r relation, anchor, target interface{}, maxDistance float64) *Selector {
+	return &Selector{
+		finder: &relative{
+			relation:    r,
+			anchor:      anchor,
+			target:      target,
+			maxDistance: maxDistance,
+		},
+	}
+}
+
+// find implements finder
+func (r *relative) find(e *Ebitest, bsel *Selector, region *image.NRGBA, all bool) ([]*Selector, *Selector) {
+	tsels, tsel := e.findSelectors(region, r.target, findAllSelectors, emptyRec)
+
+	// The target is the one used on the failure messages
+	// with the anchor position for the description
+	fr := *r
+	fr.anchorRect, fr.anchorFound = r.anchorRec(e, region)
+	fsel := *tsel
+	fsel.finder = &fr
+	if !fr.anchorFound {
+		return nil, &fsel
+	}
+
+	sels := make([]*Selector, 0)
+	for _, s := range tsels {
+		if !r.matches(fr.anchorRect, s.rect) {
+			continue
+		}
+		// The found ones can be searched again with the same relation
+		s.finder = r
+		sels = append(sels, s)
+	}
+
+	a := fr.anchorRect
+	sort.SliceStable(sels, func(i, j int) bool {
+		return rectDistance(a, sels[i].rect) < rectDistance(a, sels[j].rect)
+	})
+	if !all && len(sels) > 1 {
+		sels = sels[:1]
+	}
+
+	return sels, &fsel
+}
+
+// anchorRec returns the rectangle of the anchor and if it was found on the region
+func (r *relative) anchorRec(e *Ebitest, region *image.NRGBA) (image.Rectangle, bool) {
+	switch v := r.anchor.(type) {
+	case image.Rectangle:
+		return v, true
+	case *Selector:
+		if v.rect != emptyRec {
+			return v.rect, true
+		}
+	}
+
+	sels, _ := e.findSelectors(region, r.anchor, !findAllSelectors, emptyRec)
+	if len(sels) == 0 {
+		return emptyRec, false
+	}
+	return sels[0].rect, true
+}
+
+// matches checks if the target t is on the relation with the anchor a
+func (r *relative) matches(a, t image.Rectangle) bool {
+	switch r.relation {
+	case relationBelow:
+		return t.Min.Y >= a.Max.Y
+	case relationAbove:
+		return t.Max.Y <= a.Min.Y
+	case relationRightOf:
+		return t.Min.X >= a.Max.X
+	case relationLeftOf:
+		return t.Max.X <= a.Min.X
+	case relationNear:
+		return t != a && rectDistance(a, t) <= r.maxDistance
+	case relationSameRow:
+		return t != a && t.Min.Y < a.Max.Y && a.Min.Y < t.Max.Y
+	}
+	return false
+}
+
+// describe implements describer
+func (r *relative) describe() string {
+	var d string
+	switch r.relation {
+	case relationBelow:
+		d = "below"
+	case relationAbove:
+		d = "above"
+	case relationRightOf:
+		d = "right of"
+	case relationLeftOf:
+		d = "left of"
+	case relationNear:
+		d = fmt.Sprintf("near (%v px)", r.maxDistance)
+	case relationSameRow:
+		d = "on the same row as"
+	}
+	if !r.anchorFound {
+		return d + " the anchor, which was not found"
+	}
+	return fmt.Sprintf("%s the anchor at %v", d, r.anchorRect)
+}
+
+// rectDistance returns the distance between the closest points of a and b,
+// which is 0 if they overlap
+func rectDistance(a, b image.Rectangle) float64 {
+	dx := max(0, a.Min.X-b.Max.X, b.Min.X-a.Max.X)
+	dy := max(0, a.Min.Y-b.Max.Y, b.Min.Y-a.Max.Y)
+	return math.Hypot(float64(dx), float64(dy))
+}
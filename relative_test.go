@@ -0,0 +1,50 @@
+package ebitest
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRectDistance(t *testing.T) {
+	a := image.Rect(10, 10, 20, 20)
+	assert.Equal(t, 0.0, rectDistance(a, image.Rect(15, 15, 30, 30)))
+	assert.Equal(t, 5.0, rectDistance(a, image.Rect(25, 12, 30, 18)))
+	assert.Equal(t, 5.0, rectDistance(a, image.Rect(0, 25, 10, 30)))
+	assert.Equal(t, 5.0, rectDistance(a, image.Rect(23, 24, 30, 30)))
+}
+
+func TestRelativeMatches(t *testing.T) {
+	a := image.Rect(10, 10, 20, 20)
+	below := image.Rect(10, 25, 20, 30)
+	above := image.Rect(12, 0, 18, 8)
+	right := image.Rect(30, 12, 40, 18)
+	left := image.Rect(0, 15, 5, 25)
+
+	tcs := []struct {
+		name string
+		sel  *Selector
+		exp  []image.Rectangle
+	}{
+		{name: "Below", sel: Below(a, nil), exp: []image.Rectangle{below}},
+		{name: "Above", sel: Above(a, nil), exp: []image.Rectangle{above}},
+		{name: "RightOf", sel: RightOf(a, nil), exp: []image.Rectangle{right}},
+		{name: "LeftOf", sel: LeftOf(a, nil), exp: []image.Rectangle{left}},
+		{name: "Near", sel: Near(a, nil, 5), exp: []image.Rectangle{below, above, left}},
+		{name: "SameRowAs", sel: SameRowAs(a, nil), exp: []image.Rectangle{right, left}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := tc.sel.finder.(*relative)
+			got := make([]image.Rectangle, 0)
+			for _, tr := range []image.Rectangle{a, below, above, right, left} {
+				if r.matches(a, tr) {
+					got = append(got, tr)
+				}
+			}
+			assert.Equal(t, tc.exp, got)
+		})
+	}
+}
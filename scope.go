@@ -0,0 +1,99 @@
+package ebitest
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// notFoundScopeMessage is the failure message of the searches
+	// within a Selector that was not returned by the searches
+	notFoundScopeMessage = "searching within a selector that was not found"
+)
+
+// Scope restricts all the searches to a part of the screen, the
+// returned selectors still have the position relative to the screen.
+// The Scope of a Selector that was not found has no et
+type Scope struct {
+	et   *Ebitest
+	rect image.Rectangle
+}
+
+// Rec returns the image.Rectangle in which the searches are done
+func (s *Scope) Rec() image.Rectangle {
+	return s.rect
+}
+
+// Find returns the first selector(ss) found inside of the Scope
+// ss can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (s *Scope) Find(ss interface{}) (*Selector, bool) {
+	if s.et == nil {
+		return nil, false
+	}
+	s.et.PingPong.Ping()
+	sc := s.et.game.GetScreen()
+
+	sel, ok := s.et.findSelector(sc, ss, s.rect)
+	if !ok {
+		return nil, false
+	}
+	return sel, true
+}
+
+// Should checks if selector(ss) is present inside of the Scope and returns it
+// ss can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (s *Scope) Should(t *testing.T, ss interface{}) (*Selector, bool) {
+	t.Helper()
+	if s.et == nil {
+		assert.Fail(t, notFoundScopeMessage)
+		return nil, false
+	}
+	return s.et.should(t, ss, s.rect)
+}
+
+// ShouldNot checks if selector(ss) is not present inside of the Scope
+// ss can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (s *Scope) ShouldNot(t *testing.T, ss interface{}) bool {
+	t.Helper()
+	if s.et == nil {
+		assert.Fail(t, notFoundScopeMessage)
+		return false
+	}
+	return s.et.shouldNot(t, ss, s.rect)
+}
+
+// Must checks if selector(ss) is present inside of the Scope and returns it.
+// If it's not present it'll fail the test
+// ss can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (s *Scope) Must(t *testing.T, ss interface{}) *Selector {
+	t.Helper()
+	if s.et == nil {
+		require.Fail(t, notFoundScopeMessage)
+		return nil
+	}
+	return s.et.must(t, ss, s.rect)
+}
+
+// MustNot checks if selector(ss) is not present inside of the Scope.
+// If it's present it'll fail the test
+// ss can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (s *Scope) MustNot(t *testing.T, ss interface{}) {
+	t.Helper()
+	if s.et == nil {
+		require.Fail(t, notFoundScopeMessage)
+		return
+	}
+	s.et.mustNot(t, ss, s.rect)
+}
+
+// GetAll returns all the repeated instances of ss inside of the Scope or none if nothing is found,
+// the opts are the same as for Ebitest.GetAll
+func (s *Scope) GetAll(ss interface{}, opts ...getAllOptionsFn) []*Selector {
+	if s.et == nil {
+		return nil
+	}
+	return s.et.getAll(ss, s.rect, opts...)
+}
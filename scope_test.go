@@ -0,0 +1,81 @@
+package ebitest
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreenRegion(t *testing.T) {
+	sc := newSolidImage(60, 40, testBackground)
+
+	assert.Same(t, sc, screenRegion(sc, emptyRec))
+
+	r := screenRegion(sc, image.Rect(10, 5, 30, 20))
+	assert.Equal(t, image.Rect(10, 5, 30, 20), r.Bounds())
+	// It's the same pixels not a copy
+	r.SetNRGBA(10, 5, testRed)
+	assert.Equal(t, testRed, sc.NRGBAAt(10, 5))
+
+	// The parts outside of the screen are clipped
+	assert.Equal(t, image.Rect(50, 30, 60, 40), screenRegion(sc, image.Rect(50, 30, 80, 70)).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 10, 10), screenRegion(sc, image.Rect(-10, -10, 10, 10)).Bounds())
+	assert.True(t, screenRegion(sc, image.Rect(100, 100, 110, 110)).Bounds().Empty())
+}
+
+func TestScopeFind(t *testing.T) {
+	sc := newSolidImage(60, 40, testBackground)
+	fillRect(sc, image.Rect(10, 10, 15, 15), testRed)
+	fillRect(sc, image.Rect(40, 20, 45, 25), testRed)
+	fillRect(sc, image.Rect(38, 18, 39, 19), testGreen)
+	e, _ := newTestEbitest(t, sc)
+	red := newSolidImage(5, 5, testRed)
+
+	// The found ones have the position relative to the screen
+	scope := e.Within(image.Rect(30, 15, 60, 40))
+	sel, ok := scope.Find(red)
+	require.True(t, ok)
+	assert.Equal(t, image.Rect(40, 20, 45, 25), sel.Rec())
+	assert.Len(t, scope.GetAll(red), 1)
+	assert.Len(t, e.GetAll(red), 2)
+
+	// It can only be found if it's all inside
+	_, ok = e.Within(image.Rect(0, 0, 14, 14)).Find(red)
+	assert.False(t, ok)
+
+	// Inside of a Selector as with its Rec
+	area := e.Must(t, Near(newSolidImage(1, 1, testGreen), red, 5))
+	require.Equal(t, image.Rect(40, 20, 45, 25), area.Rec())
+	sel, ok = area.Find(red)
+	require.True(t, ok)
+	assert.Equal(t, image.Rect(40, 20, 45, 25), sel.Rec())
+	assert.Equal(t, image.Rect(40, 20, 45, 25), area.Must(t, red).Rec())
+	_, ok = area.Find(newSolidImage(1, 1, testGreen))
+	assert.False(t, ok)
+}
+
+func TestScopeNotFoundSelector(t *testing.T) {
+	red := newSolidImage(5, 5, testRed)
+
+	var nilSel *Selector
+	for _, sel := range []*Selector{nilSel, NewFromImage(red)} {
+		scope := sel.Within()
+		assert.Equal(t, emptyRec, scope.Rec())
+		assert.Nil(t, scope.GetAll(red))
+
+		_, ok := sel.Find(red)
+		assert.False(t, ok)
+
+		// The assertions fail instead of panicking
+		ft := &testing.T{}
+		_, ok = sel.Should(ft, red)
+		assert.False(t, ok)
+		assert.True(t, ft.Failed())
+
+		ft = &testing.T{}
+		assert.False(t, scope.ShouldNot(ft, red))
+		assert.True(t, ft.Failed())
+	}
+}
@@ -0,0 +1,475 @@
+package ebitest
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// maxAnchors is the maximum number of anchors used to filter
+	// the candidate locations, if more would be needed (because
+	// of a low threshold) all the locations are checked
+	maxAnchors = 16
+)
+
+// template is an image prepared to be searched on a screen
+type template struct {
+	w, h int
+
+	// pixels are the ones that participate on the matching
+	pixels []tpixel
+
+	// maxX and maxY are the maximum position of the participating pixels
+	maxX, maxY int
+
+	// shape is set when only the shape of the pixels has to match, so
+	// all of them have to be of the same color (any) on the screen and
+	// the background pixels have to be of a different one
+	shape      bool
+	background []tpixel
+
+	// ssim are the windows when the template is compared by
+	// the structural similarity instead of by the pixels
+	ssim []ssimBlock
+}
+
+// tpixel is a participating pixel of a template
+type tpixel struct {
+	x, y int
+	c    color.NRGBA
+}
+
+// match is a location on the screen where a template was found
+type match struct {
+	rect  image.Rectangle
+	score float64
+
+	// color is the color of the pixels on the screen
+	// when matching the shape of the template
+	color color.NRGBA
+}
+
+// searchOptions are the options used when searching a template
+type searchOptions struct {
+	ct        ColorTolerance
+	threshold float64
+	all       bool
+	workers   int
+}
+
+// newTemplate returns a template from i in which only the
+// pixels with at least an alpha of alphaThreshold participate
+// on the matching
+func newTemplate(i image.Image, alphaThreshold uint8) *template {
+	img := toNRGBA(i)
+	b := img.Bounds()
+	t := &template{
+		w: b.Dx(),
+		h: b.Dy(),
+	}
+
+	for x := range t.w {
+		for y := range t.h {
+			c := img.NRGBAAt(b.Min.X+x, b.Min.Y+y)
+			// If the source it's transparent we ignore it
+			// we want to only compare colors so we consider
+			// it as good
+			if c.A < alphaThreshold {
+				continue
+			}
+			t.pixels = append(t.pixels, tpixel{x: x, y: y, c: c})
+			t.maxX = max(t.maxX, x)
+			t.maxY = max(t.maxY, y)
+		}
+	}
+
+	return t
+}
+
+// newShapeTemplate returns a template from i that matches the shape of the pixels
+// with at least an alpha of alphaThreshold regardless of the color they have on the screen.
+// The fully transparent pixels are the background that has to be of a different color
+func newShapeTemplate(i image.Image, alphaThreshold uint8) *template {
+	t := newTemplate(i, alphaThreshold)
+	t.shape = true
+
+	img := toNRGBA(i)
+	b := img.Bounds()
+	for x := range t.w {
+		for y := range t.h {
+			c := img.NRGBAAt(b.Min.X+x, b.Min.Y+y)
+			if c.A != 0 {
+				continue
+			}
+			t.background = append(t.background, tpixel{x: x, y: y, c: c})
+			t.maxX = max(t.maxX, x)
+			t.maxY = max(t.maxY, y)
+		}
+	}
+
+	return t
+}
+
+// toNRGBA returns i as an *image.NRGBA converting it if needed
+func toNRGBA(i image.Image) *image.NRGBA {
+	if img, ok := i.(*image.NRGBA); ok {
+		return img
+	}
+	b := i.Bounds()
+	img := image.NewNRGBA(b)
+	draw.Draw(img, b, i, b.Min, draw.Src)
+	return img
+}
+
+// search returns all the matches of t on sc, if so.all is false
+// only the first one is returned. The matches are sorted by
+// column and then by row. The search is done with so.workers goroutines
+func (t *template) search(sc *image.NRGBA, so searchOptions) []match {
+	sb := sc.Bounds()
+	// The locations are the positions in which the template
+	// fits inside of the screen
+	lw := sb.Dx() - t.maxX
+	lh := sb.Dy() - t.maxY
+	if lw <= 0 || lh <= 0 {
+		return nil
+	}
+
+	// If all the pixels are transparent or ignored there
+	// is nothing to compare so it can not be found
+	total := t.total()
+	if total == 0 {
+		return nil
+	}
+
+	// maxMisses is the number of pixels that can not match
+	// and still reach the threshold
+	maxMisses := total - int(math.Ceil(so.threshold*float64(total)))
+
+	// If a location matches at least one of maxMisses+1 pixels has
+	// to match, so only the locations in which one of those anchors
+	// matches are candidates to be checked. If all the pixels can
+	// miss (threshold 0) any location matches so there are no anchors
+	var candidates []bool
+	if !t.shape && t.ssim == nil && maxMisses < total && maxMisses+1 <= maxAnchors {
+		candidates = t.candidates(sc, lw, lh, maxMisses+1, so.ct)
+	}
+
+	// The locations are split in bands of columns that are searched
+	// concurrently, the results of each band are kept separated so they
+	// can be joined in order. When searching for the first match the bands
+	// after the first one with a match stop searching
+	bands := splitBands(lw, so.workers)
+	results := make([][]match, len(bands))
+	var firstBand atomic.Int64
+	firstBand.Store(int64(len(bands)))
+
+	var wg sync.WaitGroup
+	for bi, b := range bands {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for x := b.Min; x < b.Max; x++ {
+				if !so.all && firstBand.Load() < int64(bi) {
+					return
+				}
+				for y := range lh {
+					if candidates != nil && !candidates[x*lh+y] {
+						continue
+					}
+					m, ok := t.matchAt(sc, x, y, so.ct, maxMisses)
+					if !ok {
+						continue
+					}
+					results[bi] = append(results[bi], m)
+					if !so.all {
+						storeMin(&firstBand, int64(bi))
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	matches := make([]match, 0)
+	for _, r := range results {
+		matches = append(matches, r...)
+		if !so.all && len(matches) != 0 {
+			return matches[:1]
+		}
+	}
+
+	return matches
+}
+
+// band is a range of columns [Min, Max)
+type band struct {
+	Min, Max int
+}
+
+// splitBands splits the w columns in n bands of the same size
+func splitBands(w, n int) []band {
+	n = max(1, min(n, w))
+	bands := make([]band, 0, n)
+	size := w / n
+	rest := w % n
+	var x int
+	for i := range n {
+		bw := size
+		if i < rest {
+			bw++
+		}
+		bands = append(bands, band{Min: x, Max: x + bw})
+		x += bw
+	}
+	return bands
+}
+
+// storeMin stores v on a if it's lower than the current value
+func storeMin(a *atomic.Int64, v int64) {
+	for {
+		c := a.Load()
+		if v >= c || a.CompareAndSwap(c, v) {
+			return
+		}
+	}
+}
+
+// candidates returns which locations (indexed as x*lh+y) have at least one of the
+// n anchors matching. The anchors are the pixels with the rarest colors on sc
+func (t *template) candidates(sc *image.NRGBA, lw, lh, n int, ct ColorTolerance) []bool {
+	sb := sc.Bounds()
+
+	// Count how many times each color is on the screen, as
+	// the screens normally have big areas of the same color
+	// the consecutive pixels are counted together
+	histogram := make(map[color.NRGBA]int)
+	for y := range sb.Dy() {
+		var (
+			last  color.NRGBA
+			count int
+		)
+		for x := range sb.Dx() {
+			c := nrgbaAt(sc, x, y)
+			if c != last && count != 0 {
+				histogram[last] += count
+				count = 0
+			}
+			last = c
+			count++
+		}
+		histogram[last] += count
+	}
+
+	// For each color of the template find which colors
+	// of the screen match it and how many times
+	type colorMatches struct {
+		colors map[color.NRGBA]struct{}
+		count  int
+	}
+	tcolors := make(map[color.NRGBA]*colorMatches)
+	for _, p := range t.pixels {
+		if _, ok := tcolors[p.c]; ok {
+			continue
+		}
+		cm := &colorMatches{colors: make(map[color.NRGBA]struct{})}
+		for c, n := range histogram {
+			if pixelEqual(c, p.c, ct) {
+				cm.colors[c] = struct{}{}
+				cm.count += n
+			}
+		}
+		tcolors[p.c] = cm
+	}
+
+	anchors := make([]tpixel, len(t.pixels))
+	copy(anchors, t.pixels)
+	sort.SliceStable(anchors, func(i, j int) bool {
+		return tcolors[anchors[i].c].count < tcolors[anchors[j].c].count
+	})
+	anchors = anchors[:min(n, len(anchors))]
+
+	// anchorsByColor has for each color of the screen the anchors it matches
+	anchorsByColor := make(map[color.NRGBA][]tpixel)
+	for _, a := range anchors {
+		for c := range tcolors[a.c].colors {
+			anchorsByColor[c] = append(anchorsByColor[c], a)
+		}
+	}
+
+	candidates := make([]bool, lw*lh)
+	for y := range sb.Dy() {
+		var (
+			last    color.NRGBA
+			lastAnc []tpixel
+		)
+		for x := range sb.Dx() {
+			if c := nrgbaAt(sc, x, y); c != last || x == 0 {
+				last = c
+				lastAnc = anchorsByColor[c]
+			}
+			for _, a := range lastAnc {
+				lx, ly := x-a.x, y-a.y
+				if lx < 0 || ly < 0 || lx >= lw || ly >= lh {
+					continue
+				}
+				candidates[lx*lh+ly] = true
+			}
+		}
+	}
+
+	return candidates
+}
+
+// total returns the number of pixels that are compared
+func (t *template) total() int {
+	return len(t.pixels) + len(t.background)
+}
+
+// matchAt returns the match of t on sc at the x, y of the screen. If more
+// than maxMisses pixels do not match it stops and returns false
+func (t *template) matchAt(sc *image.NRGBA, x, y int, ct ColorTolerance, maxMisses int) (match, bool) {
+	var (
+		score float64
+		c     color.NRGBA
+		ok    bool
+	)
+	if t.ssim != nil {
+		// The SSIM does not have misses so they are
+		// converted to the minimum score to reach
+		total := float64(t.total())
+		score, ok = t.ssimScoreAt(sc, x, y, (total-float64(maxMisses))/total)
+	} else if t.shape {
+		score, c, ok = t.shapeScoreAt(sc, x, y, ct, maxMisses)
+	} else {
+		score, ok = t.scoreAt(sc, x, y, ct, maxMisses)
+	}
+	if !ok {
+		return match{}, false
+	}
+
+	return match{
+		rect:  image.Rect(x, y, x+t.w, y+t.h),
+		score: score,
+		color: c,
+	}, true
+}
+
+// shapeScoreAt returns the fraction of the pixels of t that match the shape on sc
+// at the x, y of the screen and the color the shape has. The color is the one of
+// the first pixel, all the others have to be the same and the background different.
+// If more than maxMisses pixels do not match it stops and returns false
+func (t *template) shapeScoreAt(sc *image.NRGBA, x, y int, ct ColorTolerance, maxMisses int) (float64, color.NRGBA, bool) {
+	if len(t.pixels) == 0 {
+		return 0, color.NRGBA{}, false
+	}
+	c := nrgbaAt(sc, x+t.pixels[0].x, y+t.pixels[0].y)
+
+	var misses int
+	for _, p := range t.pixels[1:] {
+		if !pixelEqual(nrgbaAt(sc, x+p.x, y+p.y), c, ct) {
+			misses++
+			if misses > maxMisses {
+				return 0, c, false
+			}
+		}
+	}
+	for _, p := range t.background {
+		if pixelEqual(nrgbaAt(sc, x+p.x, y+p.y), c, ct) {
+			misses++
+			if misses > maxMisses {
+				return 0, c, false
+			}
+		}
+	}
+
+	total := t.total()
+	return float64(total-misses) / float64(total), c, true
+}
+
+// scoreAt returns the fraction of the pixels of t that match sc at the x, y
+// of the screen. If more than maxMisses pixels do not match it stops and returns false
+func (t *template) scoreAt(sc *image.NRGBA, x, y int, ct ColorTolerance, maxMisses int) (float64, bool) {
+	var misses int
+	for _, p := range t.pixels {
+		if !pixelEqual(nrgbaAt(sc, x+p.x, y+p.y), p.c, ct) {
+			misses++
+			if misses > maxMisses {
+				return 0, false
+			}
+		}
+	}
+	total := len(t.pixels)
+	return float64(total-misses) / float64(total), true
+}
+
+// nrgbaAt returns the color of sc at x, y relative to the bounds of sc
+func nrgbaAt(sc *image.NRGBA, x, y int) color.NRGBA {
+	i := y*sc.Stride + x*4
+	s := sc.Pix[i : i+4 : i+4]
+	return color.NRGBA{s[0], s[1], s[2], s[3]}
+}
+
+// best returns the location of sc with the highest score for t
+// if any is above minScore. As it's used to describe the failures
+// it's not exhaustive, the locations checked are the ones in which
+// at least one of the anchors (the rarest colors) matches
+func (t *template) best(sc *image.NRGBA, ct ColorTolerance, minScore float64, workers int) (match, bool) {
+	sb := sc.Bounds()
+	lw := sb.Dx() - t.maxX
+	lh := sb.Dy() - t.maxY
+	total := t.total()
+	if total == 0 || lw <= 0 || lh <= 0 {
+		return match{}, false
+	}
+
+	// Checking all the locations with a low minScore is too slow, and
+	// a location close to match has most of its pixels matching so
+	// it's very likely that one of the anchors matches too
+	var candidates []bool
+	if !t.shape && t.ssim == nil {
+		candidates = t.candidates(sc, lw, lh, maxAnchors, ct)
+	}
+
+	bands := splitBands(lw, workers)
+	results := make([]match, len(bands))
+
+	var wg sync.WaitGroup
+	for bi, b := range bands {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bestScore := minScore
+			for x := b.Min; x < b.Max; x++ {
+				for y := range lh {
+					if candidates != nil && !candidates[x*lh+y] {
+						continue
+					}
+					// Only the locations that improve the current
+					// best are fully checked
+					maxMisses := total - int(math.Floor(bestScore*float64(total))) - 1
+					m, ok := t.matchAt(sc, x, y, ct, maxMisses)
+					if ok && m.score > bestScore {
+						bestScore = m.score
+						results[bi] = m
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var bm match
+	for _, m := range results {
+		if m.score > bm.score {
+			bm = m
+		}
+	}
+
+	return bm, bm.score != 0
+}
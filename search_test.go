@@ -0,0 +1,398 @@
+package ebitest
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"log"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestScreen returns a screen of w*h with a background and some
+// random rectangles and noise so it looks like a simple UI
+func newTestScreen(w, h int) *image.NRGBA {
+	r := rand.New(rand.NewSource(42))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	bg := color.NRGBA{0x13, 0x1a, 0x22, 0xff}
+	for x := range w {
+		for y := range h {
+			img.SetNRGBA(x, y, bg)
+		}
+	}
+
+	for range 20 {
+		c := color.NRGBA{uint8(r.Intn(256)), uint8(r.Intn(256)), uint8(r.Intn(256)), 0xff}
+		x, y := r.Intn(w), r.Intn(h)
+		rw, rh := r.Intn(w/4)+1, r.Intn(h/4)+1
+		for i := x; i < min(x+rw, w); i++ {
+			for j := y; j < min(y+rh, h); j++ {
+				img.SetNRGBA(i, j, c)
+			}
+		}
+	}
+
+	for range w * h / 20 {
+		v := uint8(r.Intn(256))
+		img.SetNRGBA(r.Intn(w), r.Intn(h), color.NRGBA{v, v, v, 0xff})
+	}
+
+	return img
+}
+
+// newTestSelector returns a copy of the rect of sc with
+// the border pixels transparent as it happens with texts
+func newTestSelector(sc *image.NRGBA, rect image.Rectangle) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for x := range rect.Dx() {
+		for y := range rect.Dy() {
+			if x == 0 || y == 0 {
+				continue
+			}
+			img.SetNRGBA(x, y, sc.NRGBAAt(rect.Min.X+x, rect.Min.Y+y))
+		}
+	}
+	return img
+}
+
+// bruteForceSearch is the reference implementation that checks
+// every pixel of the screen against every pixel of the selector
+func bruteForceSearch(sc, sub image.Image, ct ColorTolerance, all bool) []image.Rectangle {
+	rects := make([]image.Rectangle, 0)
+	sx, sy := sc.Bounds().Dx(), sc.Bounds().Dy()
+	bx, by := sub.Bounds().Dx(), sub.Bounds().Dy()
+	for x := range sx {
+		for y := range sy {
+			if hasImageAt(sc, sub, x, y, ct) {
+				rects = append(rects, image.Rect(x, y, x+bx, y+by))
+				if !all {
+					return rects
+				}
+			}
+		}
+	}
+	return rects
+}
+
+// hasImageAt checks if the image sub is image i at the ix, iy
+func hasImageAt(i, sub image.Image, ix, iy int, ct ColorTolerance) bool {
+	sx, sy := sub.Bounds().Dx(), sub.Bounds().Dy()
+	for x := range sx {
+		for y := range sy {
+			sc := sub.At(x, y)
+			if sc.(color.NRGBA).A != 255 {
+				continue
+			}
+			if !image.Pt(ix+x, iy+y).In(i.Bounds()) || !equalColors(sc, i.At(ix+x, iy+y), ct) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchRects(ms []match) []image.Rectangle {
+	rects := make([]image.Rectangle, 0, len(ms))
+	for _, m := range ms {
+		rects = append(rects, m.rect)
+	}
+	return rects
+}
+
+func TestTemplateSearch(t *testing.T) {
+	sc := newTestScreen(160, 120)
+	tcs := []struct {
+		name string
+		rect image.Rectangle
+		ct   ColorTolerance
+	}{
+		{name: "Exact", rect: image.Rect(30, 40, 50, 50)},
+		{name: "Background", rect: image.Rect(0, 0, 3, 3)},
+		{name: "ColorTolerance", rect: image.Rect(100, 20, 130, 40), ct: ColorTolerance{Delta: 10}},
+		{name: "DeltaE", rect: image.Rect(70, 70, 80, 100), ct: ColorTolerance{DeltaE: 5}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			sub := newTestSelector(sc, tc.rect)
+			tmpl := newTemplate(sub, 255)
+
+			for _, all := range []bool{true, false} {
+				exp := bruteForceSearch(sc, sub, tc.ct, all)
+				ms := tmpl.search(sc, searchOptions{ct: tc.ct, threshold: 1, all: all})
+				require.NotEmpty(t, exp)
+				assert.Equal(t, exp, matchRects(ms))
+			}
+		})
+	}
+}
+
+func TestNewTemplate(t *testing.T) {
+	pal := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{
+		color.Transparent,
+		color.NRGBA{255, 0, 0, 255},
+		color.NRGBA{0, 255, 0, 128},
+	})
+	pal.SetColorIndex(0, 0, 1)
+	pal.SetColorIndex(1, 1, 2)
+
+	tmpl := newTemplate(pal, 255)
+	assert.Equal(t, []tpixel{{x: 0, y: 0, c: color.NRGBA{255, 0, 0, 255}}}, tmpl.pixels)
+
+	tmpl = newTemplate(pal, 100)
+	assert.Equal(t, []tpixel{
+		{x: 0, y: 0, c: color.NRGBA{255, 0, 0, 255}},
+		{x: 1, y: 1, c: color.NRGBA{0, 255, 0, 128}},
+	}, tmpl.pixels)
+	assert.Equal(t, 1, tmpl.maxX)
+	assert.Equal(t, 1, tmpl.maxY)
+}
+
+func TestShapeTemplateSearch(t *testing.T) {
+	sc := image.NewNRGBA(image.Rect(0, 0, 60, 30))
+	bg := color.NRGBA{0x13, 0x1a, 0x22, 0xff}
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+	for x := range 60 {
+		for y := range 30 {
+			sc.SetNRGBA(x, y, bg)
+		}
+	}
+	// A solid block that should not match as the
+	// background of the shape is also of the color
+	for x := 45; x < 55; x++ {
+		for y := 5; y < 15; y++ {
+			sc.SetNRGBA(x, y, red)
+		}
+	}
+
+	// A plus shape
+	sub := image.NewNRGBA(image.Rect(0, 0, 5, 5))
+	for i := range 5 {
+		sub.SetNRGBA(2, i, color.NRGBA{255, 255, 255, 255})
+		sub.SetNRGBA(i, 2, color.NRGBA{255, 255, 255, 255})
+	}
+	for _, p := range []struct {
+		x int
+		c color.NRGBA
+	}{{x: 10, c: red}, {x: 30, c: blue}} {
+		for i := range 5 {
+			sc.SetNRGBA(p.x+2, 10+i, p.c)
+			sc.SetNRGBA(p.x+i, 12, p.c)
+		}
+	}
+
+	ms := newShapeTemplate(sub, 255).search(sc, searchOptions{threshold: 1, all: true})
+	require.Len(t, ms, 2)
+	assert.Equal(t, image.Rect(10, 10, 15, 15), ms[0].rect)
+	assert.Equal(t, red, ms[0].color)
+	assert.Equal(t, image.Rect(30, 10, 35, 15), ms[1].rect)
+	assert.Equal(t, blue, ms[1].color)
+}
+
+func TestSelectorMask(t *testing.T) {
+	e := &Ebitest{}
+	sc := newTestScreen(160, 120)
+	rect := image.Rect(30, 40, 50, 50)
+	sub := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(sub, sub.Bounds(), sc, rect.Min, draw.Src)
+
+	// The mask has a hole in the middle that can change
+	hole := image.Rect(5, 3, 10, 7)
+	mask := image.NewAlpha(sub.Bounds())
+	draw.Draw(mask, mask.Bounds(), image.Opaque, image.Point{}, draw.Src)
+	draw.Draw(mask, hole, image.Transparent, image.Point{}, draw.Src)
+	draw.Draw(sc, hole.Add(rect.Min), image.NewUniform(color.NRGBA{255, 0, 255, 255}), image.Point{}, draw.Src)
+
+	find := func(ss interface{}) []image.Rectangle {
+		sels, _ := e.findSelectors(sc, ss, findAllSelectors, emptyRec)
+		rs := make([]image.Rectangle, 0, len(sels))
+		for _, s := range sels {
+			rs = append(rs, s.Rec())
+		}
+		return rs
+	}
+
+	assert.Empty(t, find(sub))
+	assert.Equal(t, []image.Rectangle{rect}, find(NewFromImageWithMask(sub, mask)))
+
+	// The pixels outside of the hole still have to match
+	sc.SetNRGBA(rect.Min.X, rect.Min.Y, color.NRGBA{255, 0, 255, 255})
+	assert.Empty(t, find(NewFromImageWithMask(sub, mask)))
+
+	// With all the pixels masked there is nothing to compare
+	assert.Empty(t, find(NewFromImageWithMask(sub, image.NewAlpha(sub.Bounds()))))
+
+	// The masked pixels are greyed out on the dumps
+	di := NewFromImageWithMask(sub, mask).dumpImage(sc, ColorTolerance{}, defaultAlphaThreshold)
+	assert.Equal(t, color.NRGBAModel.Convert(ignoredDumpColor), color.NRGBAModel.Convert(di.At(hole.Min.X, hole.Min.Y)))
+	assert.Equal(t, sub.At(1, 1), di.At(1, 1))
+	assert.Same(t, sub, NewFromImage(sub).dumpImage(sc, ColorTolerance{}, defaultAlphaThreshold))
+}
+
+func TestSelectorIgnoreColor(t *testing.T) {
+	e := &Ebitest{}
+	sc := newTestScreen(160, 120)
+	rect := image.Rect(30, 40, 50, 50)
+	placeholder := color.NRGBA{255, 0, 255, 255}
+	sub := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(sub, sub.Bounds(), sc, rect.Min, draw.Src)
+	for x := range 5 {
+		sub.SetNRGBA(x, 0, placeholder)
+		sub.SetNRGBA(x+10, 5, placeholder)
+	}
+
+	find := func(ss interface{}) bool {
+		_, ok := e.findSelector(sc, ss, emptyRec)
+		return ok
+	}
+
+	assert.False(t, find(sub))
+	sel := NewFromImage(sub).WithIgnoreColor(color.RGBA{0, 0, 0, 255}).WithIgnoreColor(placeholder)
+	assert.True(t, sel.isIgnored(sub, 0, 0))
+	assert.False(t, sel.isIgnored(sub, 0, 1))
+	assert.True(t, find(sel))
+
+	// The ignored color matches any color of the screen
+	for _, c := range []color.NRGBA{{0, 0, 0, 255}, {255, 255, 255, 255}, {1, 2, 3, 0}} {
+		sc.SetNRGBA(rect.Min.X+12, rect.Min.Y+5, c)
+		assert.True(t, find(sel))
+	}
+
+	// With all the pixels ignored there is nothing to compare
+	all := image.NewNRGBA(sub.Bounds())
+	draw.Draw(all, all.Bounds(), image.NewUniform(placeholder), image.Point{}, draw.Src)
+	assert.False(t, find(NewFromImage(all).WithIgnoreColor(placeholder)))
+}
+
+func TestTemplateSearchThreshold(t *testing.T) {
+	sc := newTestScreen(160, 120)
+	rect := image.Rect(30, 40, 50, 50)
+	sub := newTestSelector(sc, rect)
+	// Change some pixels so it's not an exact match
+	for x := range 5 {
+		sub.SetNRGBA(x+1, 5, color.NRGBA{1, 2, 3, 255})
+	}
+	tmpl := newTemplate(sub, 255)
+
+	ms := tmpl.search(sc, searchOptions{threshold: 1})
+	assert.Empty(t, ms)
+
+	ms = tmpl.search(sc, searchOptions{threshold: 0.95})
+	require.Len(t, ms, 1)
+	assert.Equal(t, rect, ms[0].rect)
+	assert.InDelta(t, 1-5.0/float64(len(tmpl.pixels)), ms[0].score, 0.0001)
+
+	for _, w := range []int{1, 4} {
+		m, ok := tmpl.best(sc, ColorTolerance{}, 0.5, w)
+		require.True(t, ok)
+		assert.Equal(t, ms[0], m)
+	}
+}
+
+func TestNearMiss(t *testing.T) {
+	sc := newTestScreen(160, 120)
+	rect := image.Rect(30, 40, 50, 50)
+	sub := newTestSelector(sc, rect)
+	for x := range 5 {
+		sub.SetNRGBA(x+1, 5, color.NRGBA{1, 2, 3, 255})
+	}
+	e := &Ebitest{}
+
+	sel := NewFromImage(sub)
+	msg := e.notFoundMessage(sc, sel, emptyRec)
+	assert.Equal(t, rect, sel.Rec())
+	assert.Contains(t, msg, "best match: score")
+
+	// With SSIM it's only searched if the failures are dumped or logged
+	sel = NewFromImage(sub).WithMatcher(MatchSSIM).WithMatchThreshold(1)
+	msg = e.notFoundMessage(sc, sel, emptyRec)
+	assert.Equal(t, emptyRec, sel.Rec())
+	assert.NotContains(t, msg, "best match")
+
+	WithLogger(log.New(io.Discard, "", 0))(&e.options)
+	sel = NewFromImage(sub).WithMatcher(MatchSSIM).WithMatchThreshold(1)
+	msg = e.notFoundMessage(sc, sel, emptyRec)
+	assert.Equal(t, rect, sel.Rec())
+	assert.Contains(t, msg, "best match: score")
+}
+
+func TestTemplateSearchWorkers(t *testing.T) {
+	sc := newTestScreen(160, 120)
+	sub := newTestSelector(sc, image.Rect(0, 0, 3, 3))
+	tmpl := newTemplate(sub, 255)
+
+	for _, all := range []bool{true, false} {
+		exp := tmpl.search(sc, searchOptions{threshold: 1, all: all, workers: 1})
+		require.NotEmpty(t, exp)
+		for _, w := range []int{2, 3, 8, 1000} {
+			ms := tmpl.search(sc, searchOptions{threshold: 1, all: all, workers: w})
+			assert.Equal(t, exp, ms, "workers %d", w)
+		}
+	}
+}
+
+func TestSplitBands(t *testing.T) {
+	assert.Equal(t, []band{{0, 4}, {4, 7}, {7, 10}}, splitBands(10, 3))
+	assert.Equal(t, []band{{0, 1}, {1, 2}}, splitBands(2, 5))
+	assert.Equal(t, []band{{0, 10}}, splitBands(10, 0))
+}
+
+func BenchmarkSearch(b *testing.B) {
+	sc := newTestScreen(640, 480)
+	sub := newTestSelector(sc, image.Rect(400, 300, 500, 324))
+
+	b.Run("BruteForce", func(b *testing.B) {
+		for b.Loop() {
+			bruteForceSearch(sc, sub, ColorTolerance{}, true)
+		}
+	})
+	b.Run("Template", func(b *testing.B) {
+		for b.Loop() {
+			newTemplate(sub, 255).search(sc, searchOptions{threshold: 1, all: true})
+		}
+	})
+	b.Run("TemplateColorTolerance", func(b *testing.B) {
+		for b.Loop() {
+			newTemplate(sub, 255).search(sc, searchOptions{ct: ColorTolerance{Delta: 5}, threshold: 1, all: true})
+		}
+	})
+	b.Run("TemplateThreshold", func(b *testing.B) {
+		for b.Loop() {
+			newTemplate(sub, 255).search(sc, searchOptions{threshold: 0.99, all: true})
+		}
+	})
+	b.Run("TemplateThresholdWorkers", func(b *testing.B) {
+		for b.Loop() {
+			newTemplate(sub, 255).search(sc, searchOptions{threshold: 0.99, all: true, workers: runtime.GOMAXPROCS(0)})
+		}
+	})
+}
+
+func BenchmarkNotFound(b *testing.B) {
+	sc := newTestScreen(640, 480)
+	// A selector that is not on the screen
+	sub := newTestSelector(newTestScreen(320, 240), image.Rect(200, 150, 300, 174))
+	e := &Ebitest{}
+
+	b.Run("Search", func(b *testing.B) {
+		for b.Loop() {
+			e.findSelectors(sc, NewFromImage(sub), !findAllSelectors, emptyRec)
+		}
+	})
+	b.Run("NearMiss", func(b *testing.B) {
+		for b.Loop() {
+			e.notFoundMessage(sc, NewFromImage(sub), emptyRec)
+		}
+	})
+	b.Run("NearMissSSIM", func(b *testing.B) {
+		for b.Loop() {
+			e.notFoundMessage(sc, NewFromImage(sub).WithMatcher(MatchSSIM), emptyRec)
+		}
+	})
+}
@@ -1,48 +1,312 @@
 package ebitest
 
 import (
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
+	"math"
+	"slices"
+	"testing"
 
-	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
+// ErrStaleSelector is returned when a Selector is
+// no longer on the position in which it was found
+var ErrStaleSelector = errors.New("stale selector")
+
 // Selector represents the thing you are searching for
 type Selector struct {
 	img  image.Image
 	rect image.Rectangle
 
+	// name identifies the Selector on the failure messages and dumps
+	name string
+
+	// alts are alternative images of img that
+	// are also searched as the same Selector
+	alts []image.Image
+
+	// finder is set when the Selector is not
+	// searched by the image but by the finder
+	finder finder
+
+	colorTolerance *ColorTolerance
+	matchThreshold *float64
+	matcher        *Matcher
+	scales         []float64
+	scaleFilter    *ScaleFilter
+	rotations      []float64
+	flipH          bool
+	flipV          bool
+	alphaThreshold uint8
+	mask           image.Image
+	ignoreColors   []color.NRGBA
+	anyColor       bool
+
+	score     float64
+	scale     float64
+	transform Transform
+	color     color.NRGBA
+
 	PingPong *PingPong
+
+	et *Ebitest
 }
 
-// NewFromText crates a new Selector from a txt
-func NewFromText(txt string, f text.Face, c color.Color) *Selector {
-	top := &text.DrawOptions{}
-	top.ColorScale.ScaleWithColor(c)
+// finder is implemented by the selectors that have their own way of searching
+// on the screen. The returned selectors have the position relative to the screen
+// and the Selector to use on the failure messages
+type finder interface {
+	find(e *Ebitest, bsel *Selector, region *image.NRGBA, all bool) ([]*Selector, *Selector)
+}
 
-	x, y := text.Measure(txt, f, 0)
-	rec := image.Rect(0, 0, int(x), int(y))
+// describer is implemented by the finders that add
+// information to the failure messages
+type describer interface {
+	describe() string
+}
 
-	imgp := image.NewPaletted(rec, color.Palette{color.Transparent})
-	img := ebiten.NewImageFromImage(imgp)
+// rectsDumper is implemented by the finders that have
+// extra rectangles to highlight on the dumps
+type rectsDumper interface {
+	dumpRects() []image.Rectangle
+}
 
-	text.Draw(img, txt, f, top)
+// NewFromText crates a new Selector from a txt
+func NewFromText(txt string, f text.Face, c color.Color) *Selector {
+	return NewFromImage(renderText(txt, f, c, TextLayout{}, 0, 0))
+}
 
-	return NewFromImage(ebitenImageToImage(img))
+// NewFromTextShape creates a new Selector from a txt that matches the
+// shape of the glyphs regardless of the color they have on the screen.
+// The detected color is returned on TextColor
+func NewFromTextShape(txt string, f text.Face) *Selector {
+	return NewFromText(txt, f, color.White).WithAnyColor()
 }
 
-// NewFromImage creates a new Selector from an image
+// NewFromImage creates a new Selector from an image, the image
+// is converted to NRGBA if it has any other color model
 func NewFromImage(i image.Image) *Selector {
 	return &Selector{
-		img: i,
+		img: toNRGBA(i),
 	}
 }
 
-// Click will click on the center of the Selectore
+// NewFromImageWithMask creates a new Selector from an image in which only the pixels
+// with a non transparent pixel on the mask are compared with the screen.
+// The mask is aligned with the image by the top left corner of both
+func NewFromImageWithMask(i, mask image.Image) *Selector {
+	s := NewFromImage(i)
+	s.mask = mask
+	return s
+}
+
+// WithIgnoreColor makes the pixels of this Selector with the color c
+// to not be compared with the screen, it can be called multiple times
+func (s *Selector) WithIgnoreColor(c color.Color) *Selector {
+	s.ignoreColors = append(s.ignoreColors, color.NRGBAModel.Convert(c).(color.NRGBA))
+	return s
+}
+
+// WithAnyColor makes this Selector to match by the shape of the opaque pixels
+// regardless of the color they have on the screen, all of them have to be of the
+// same color and the transparent ones of a different one. It's meant for texts
+func (s *Selector) WithAnyColor() *Selector {
+	s.anyColor = true
+	return s
+}
+
+// WithColorTolerance sets the ColorTolerance used when matching this Selector,
+// it has priority over the one set on Run
+func (s *Selector) WithColorTolerance(ct ColorTolerance) *Selector {
+	s.colorTolerance = &ct
+	return s
+}
+
+// WithMatchThreshold sets the minimum score (0-1) needed to match this Selector,
+// it has priority over the one set on Run. The values out of the range are clamped
+func (s *Selector) WithMatchThreshold(th float64) *Selector {
+	s.matchThreshold = &th
+	return s
+}
+
+// WithMatcher sets the Matcher used to compare this Selector with the
+// screen, it has priority over the one set on Run
+func (s *Selector) WithMatcher(m Matcher) *Selector {
+	s.matcher = &m
+	return s
+}
+
+// WithAlphaThreshold sets the minimum alpha (1-255) of the pixels of this Selector
+// that are compared with the screen, it has priority over the one set on Run
+func (s *Selector) WithAlphaThreshold(a uint8) *Selector {
+	s.alphaThreshold = a
+	return s
+}
+
+// WithScales sets the scales at which this Selector is searched,
+// it has priority over the ones set on Run
+func (s *Selector) WithScales(scales ...float64) *Selector {
+	s.scales = scales
+	return s
+}
+
+// WithScaleFilter sets the ScaleFilter used when scaling this Selector,
+// it has priority over the one set on Run
+func (s *Selector) WithScaleFilter(f ScaleFilter) *Selector {
+	s.scaleFilter = &f
+	return s
+}
+
+// WithRotations sets the clockwise angles in degrees at which this Selector is also
+// searched. The right angles match exactly, for the rest the rotated pixels are
+// interpolated so they usually need a WithMatchThreshold or WithColorTolerance
+func (s *Selector) WithRotations(angles ...float64) *Selector {
+	s.rotations = angles
+	return s
+}
+
+// WithFlips sets if this Selector is also searched flipped
+// horizontally (h) and/or vertically (v)
+func (s *Selector) WithFlips(h, v bool) *Selector {
+	s.flipH = h
+	s.flipV = v
+	return s
+}
+
+// base returns a copy of the Selector configuration without the
+// position so it can be used to search for it again
+func (s *Selector) base() *Selector {
+	ns := *s
+	ns.rect = emptyRec
+	ns.score = 0
+	ns.scale = 0
+	ns.transform = Transform{}
+	ns.color = color.NRGBA{}
+	return &ns
+}
+
+// Click will click on the center of the Selectore as ClickAt(Center)
 func (s *Selector) Click() {
+	s.ClickAt(Center)
+}
+
+// ClickAt will click on the Anchor a of the Selector, like
+// TopLeft, At(0.25, 0.5) or Left.Offset(-10, 0)
+func (s *Selector) ClickAt(a Anchor) {
+	p := a.point(s.rect)
+	if s.et != nil {
+		s.et.logf("click on %s at %v", s.label(), p)
+	}
+	s.PingPong.ClickPing(Ball{X: p.X, Y: p.Y})
+}
+
+// ClickVerified is like Click but it first checks that the Selector is still on the
+// same position and if not it returns an ErrStaleSelector without clicking
+func (s *Selector) ClickVerified() error {
+	return s.ClickAtVerified(Center)
+}
+
+// ClickAtVerified is like ClickAt but it first checks that the Selector is still on
+// the same position and if not it returns an ErrStaleSelector without clicking
+func (s *Selector) ClickAtVerified(a Anchor) error {
+	if !s.Exists() {
+		return fmt.Errorf("%w: %s not at %v", ErrStaleSelector, s.label(), s.rect)
+	}
+	s.ClickAt(a)
+	return nil
+}
+
+// isFound checks if the Selector was returned by a search so
+// it has a position and it can search again on the screen
+func (s *Selector) isFound() bool {
+	return s != nil && s.et != nil && s.rect != emptyRec
+}
+
+// Exists checks if the Selector is still at the same position on the current
+// frame, it's always false for the Selectors not returned by the searches
+func (s *Selector) Exists() bool {
+	if !s.isFound() {
+		return false
+	}
+
+	s.et.PingPong.Ping()
+	sc := s.et.game.GetScreen()
+
+	// The finders search also other things, like the anchor of
+	// the relative ones, that can be outside of the Selector
+	// so they are searched on all the screen
+	if s.finder != nil {
+		sels, _ := s.et.findSelectors(sc, s, findAllSelectors, emptyRec)
+		return slices.ContainsFunc(sels, func(sel *Selector) bool {
+			return sel.rect == s.rect
+		})
+	}
+
+	sel, ok := s.et.findSelector(sc, s, s.rect)
+	return ok && sel.rect == s.rect
+}
+
+// Refresh locates again the Selector on the current frame, if it's present
+// more than once the closest to the previous position is used. If it's not
+// found, or it was not returned by the searches, it returns false and the
+// Selector is not changed
+func (s *Selector) Refresh() bool {
+	if !s.isFound() {
+		return false
+	}
+
+	s.et.PingPong.Ping()
+	sc := s.et.game.GetScreen()
+
+	sels, _ := s.et.findSelectors(sc, s, findAllSelectors, emptyRec)
+	if len(sels) == 0 {
+		return false
+	}
+
 	cx, cy := s.center()
-	s.PingPong.ClickPing(Ball{X: cx, Y: cy})
+	closest := sels[0]
+	for _, sel := range sels[1:] {
+		if centerDistance(sel, cx, cy) < centerDistance(closest, cx, cy) {
+			closest = sel
+		}
+	}
+
+	*s = *closest
+	return true
+}
+
+// Within returns a Scope to search inside of the Selector, if it was
+// not returned by the searches all the searches of the Scope fail
+func (s *Selector) Within() *Scope {
+	if !s.isFound() {
+		return &Scope{}
+	}
+	return s.et.Within(s.rect)
+}
+
+// Find returns the first ss found inside of the Selector
+// ss can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (s *Selector) Find(ss interface{}) (*Selector, bool) {
+	return s.Within().Find(ss)
+}
+
+// Should checks if selector(ss) is present inside of the Selector and returns it
+// ss can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (s *Selector) Should(t *testing.T, ss interface{}) (*Selector, bool) {
+	t.Helper()
+	return s.Within().Should(t, ss)
+}
+
+// Must checks if selector(ss) is present inside of the Selector and returns it.
+// If it's not present it'll fail the test
+// ss can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (s *Selector) Must(t *testing.T, ss interface{}) *Selector {
+	t.Helper()
+	return s.Within().Must(t, ss)
 }
 
 // center returns the center of the selector
@@ -50,12 +314,174 @@ func (s *Selector) center() (int, int) {
 	return s.rect.Min.X + (s.rect.Dx() / 2), s.rect.Min.Y + (s.rect.Dy() / 2)
 }
 
+// centerDistance returns the distance from the center of s to x, y
+func centerDistance(s *Selector, x, y int) float64 {
+	sx, sy := s.center()
+	return math.Hypot(float64(sx-x), float64(sy-y))
+}
+
 // Rec returns the image.Rectangle of the image
 func (s *Selector) Rec() image.Rectangle {
 	return s.rect
 }
 
+// Name returns the name of the Selector, like the file it was loaded from
+func (s *Selector) Name() string {
+	return s.name
+}
+
+// label returns how the Selector is called on the failure messages
+func (s *Selector) label() string {
+	if s.name == "" {
+		return "selector"
+	}
+	return fmt.Sprintf("selector %q", s.name)
+}
+
+// Score returns the fraction (0-1) of the pixels of the Selector that matched
+// the screen at Rec
+func (s *Selector) Score() float64 {
+	return s.score
+}
+
+// Scale returns the scale at which the Selector matched the screen
+func (s *Selector) Scale() float64 {
+	return s.scale
+}
+
+// Transform returns the rotation and flip with which the Selector matched the screen
+func (s *Selector) Transform() Transform {
+	return s.transform
+}
+
+// TextColor returns the color that the Selector had on the screen
+// when matched with WithAnyColor or nil otherwise
+func (s *Selector) TextColor() color.Color {
+	if !s.anyColor || s.rect == emptyRec {
+		return nil
+	}
+	return s.color
+}
+
 // Image returns the underlying image
 func (s *Selector) Image() image.Image {
 	return s.img
 }
+
+// images returns the image and the alternative images of the Selector
+func (s *Selector) images() []image.Image {
+	return append([]image.Image{s.img}, s.alts...)
+}
+
+// isIgnored checks if the pixel of img at x, y (relative to the image bounds)
+// has to be ignored because of the mask or the ignore colors
+func (s *Selector) isIgnored(img image.Image, x, y int) bool {
+	if s.mask != nil {
+		mb := s.mask.Bounds()
+		if _, _, _, a := s.mask.At(mb.Min.X+x, mb.Min.Y+y).RGBA(); a == 0 {
+			return true
+		}
+	}
+	if len(s.ignoreColors) != 0 {
+		ib := img.Bounds()
+		c := color.NRGBAModel.Convert(img.At(ib.Min.X+x, ib.Min.Y+y)).(color.NRGBA)
+		for _, ic := range s.ignoreColors {
+			if c == ic {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replaceIgnored returns a copy of i with the ignored pixels
+// replaced with c, if none is ignored i is returned
+func (s *Selector) replaceIgnored(i image.Image, c color.NRGBA) image.Image {
+	if s.mask == nil && len(s.ignoreColors) == 0 {
+		return i
+	}
+
+	b := i.Bounds()
+	img := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for x := range b.Dx() {
+		for y := range b.Dy() {
+			if s.isIgnored(i, x, y) {
+				img.SetNRGBA(x, y, c)
+				continue
+			}
+			img.Set(x, y, i.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return img
+}
+
+// matchImages returns the images used to match the screen, in which
+// the ignored pixels are transparent so they are not compared
+func (s *Selector) matchImages() []image.Image {
+	imgs := make([]image.Image, 0, len(s.alts)+1)
+	for _, i := range s.images() {
+		imgs = append(imgs, s.replaceIgnored(i, color.NRGBA{}))
+	}
+	return imgs
+}
+
+// dumpImage returns the image used on the dumps, in which the ignored pixels are
+// greyed out and, if it's at a position of sc, the pixels with at least an alpha of
+// alphaThreshold that do not match sc with the ColorTolerance ct are highlighted.
+// If it has alternative images the one with less pixels that do not match is used
+func (s *Selector) dumpImage(sc image.Image, ct ColorTolerance, alphaThreshold uint8) image.Image {
+	img := s.replaceIgnored(s.img, ignoredDumpColor)
+	// Only the ones that are as on the screen can be compared
+	if s.rect == emptyRec || s.finder != nil || s.anyColor || !s.transform.isIdentity() {
+		return img
+	}
+
+	var misses []image.Point
+	found := false
+	for _, i := range s.images() {
+		if !i.Bounds().Size().Eq(s.rect.Size()) {
+			continue
+		}
+		m := s.mismatches(i, sc, ct, alphaThreshold)
+		if !found || len(m) < len(misses) {
+			img = s.replaceIgnored(i, ignoredDumpColor)
+			misses = m
+			found = true
+		}
+	}
+	if len(misses) == 0 {
+		return img
+	}
+
+	b := img.Bounds()
+	dimg := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dimg, dimg.Bounds(), img, b.Min, draw.Src)
+	for _, p := range misses {
+		dimg.SetNRGBA(p.X, p.Y, mismatchDumpColor)
+	}
+	return dimg
+}
+
+// mismatches returns the positions (relative to the image bounds) of the pixels of i
+// with at least an alpha of alphaThreshold, which are the ones compared when matching,
+// that do not match sc at the Selector position with the ColorTolerance ct
+func (s *Selector) mismatches(i, sc image.Image, ct ColorTolerance, alphaThreshold uint8) []image.Point {
+	misses := make([]image.Point, 0)
+	ib := i.Bounds()
+	for x := range ib.Dx() {
+		for y := range ib.Dy() {
+			c := color.NRGBAModel.Convert(i.At(ib.Min.X+x, ib.Min.Y+y)).(color.NRGBA)
+			if c.A < alphaThreshold || s.isIgnored(i, x, y) {
+				continue
+			}
+			p := s.rect.Min.Add(image.Pt(x, y))
+			if !p.In(sc.Bounds()) {
+				continue
+			}
+			if !pixelEqual(color.NRGBAModel.Convert(sc.At(p.X, p.Y)).(color.NRGBA), c, ct) {
+				misses = append(misses, image.Pt(x, y))
+			}
+		}
+	}
+	return misses
+}
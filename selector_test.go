@@ -0,0 +1,244 @@
+package ebitest
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEbitest returns an Ebitest with sc as the screen that answers the
+// pings as the Draw of the game does, the clicks are sent to the returned chan
+func newTestEbitest(t *testing.T, sc image.Image, opts ...optionsFn) (*Ebitest, chan Ball) {
+	pp := NewPingPong()
+	e := &Ebitest{
+		game:     &Game{screen: sc},
+		PingPong: pp,
+	}
+	for _, ofn := range opts {
+		ofn(&e.options)
+	}
+
+	clicks := make(chan Ball, 10)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-pp.ping:
+				pp.pong <- struct{}{}
+			case b := <-pp.clickPing:
+				clicks <- b
+				pp.clickPong <- struct{}{}
+			case <-done:
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() { close(done) })
+
+	return e, clicks
+}
+
+// fillRect sets all the pixels of img inside of r to c
+func fillRect(img *image.NRGBA, r image.Rectangle, c color.Color) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// newSolidImage returns an image of w*h all with the color c
+func newSolidImage(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	fillRect(img, img.Bounds(), c)
+	return img
+}
+
+var (
+	testBackground = color.NRGBA{0, 0, 0, 255}
+	testRed        = color.NRGBA{255, 0, 0, 255}
+	testGreen      = color.NRGBA{0, 255, 0, 255}
+	testBlue       = color.NRGBA{0, 0, 255, 255}
+)
+
+func TestSelectorExists(t *testing.T) {
+	sc := newSolidImage(60, 40, testBackground)
+	fillRect(sc, image.Rect(10, 10, 15, 15), testRed)
+	fillRect(sc, image.Rect(10, 20, 15, 25), testGreen)
+	fillRect(sc, image.Rect(30, 20, 35, 25), testBlue)
+	e, _ := newTestEbitest(t, sc)
+
+	red := newSolidImage(5, 5, testRed)
+	green := newSolidImage(5, 5, testGreen)
+	blue := newSolidImage(5, 5, testBlue)
+
+	tcs := []struct {
+		name string
+		ss   interface{}
+		rect image.Rectangle
+	}{
+		{name: "Image", ss: red, rect: image.Rect(10, 10, 15, 15)},
+		{name: "Below", ss: Below(red, green), rect: image.Rect(10, 20, 15, 25)},
+		{name: "RightOf", ss: RightOf(green, blue), rect: image.Rect(30, 20, 35, 25)},
+		{name: "AnyOf", ss: AnyOf(Below(green, red), Below(red, green)), rect: image.Rect(10, 20, 15, 25)},
+		{name: "AllOf", ss: AllOf(red, green), rect: image.Rect(10, 10, 15, 25)},
+		{name: "Sequence", ss: Sequence(red, blue), rect: image.Rect(10, 10, 35, 25)},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			sel := e.Must(t, tc.ss)
+			require.Equal(t, tc.rect, sel.Rec())
+			assert.True(t, sel.Exists())
+		})
+	}
+
+	t.Run("Moved", func(t *testing.T) {
+		sel := e.Must(t, Below(red, green))
+		sc := newSolidImage(60, 40, testBackground)
+		fillRect(sc, image.Rect(10, 10, 15, 15), testRed)
+		fillRect(sc, image.Rect(10, 30, 15, 35), testGreen)
+		e.game.SetScreen(sc)
+
+		assert.False(t, sel.Exists())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		var nilSel *Selector
+		assert.False(t, nilSel.Exists())
+		assert.False(t, NewFromImage(red).Exists())
+		assert.False(t, NewFromImage(red).Refresh())
+	})
+}
+
+func TestSelectorRefresh(t *testing.T) {
+	sc := newSolidImage(60, 40, testBackground)
+	fillRect(sc, image.Rect(5, 5, 10, 10), testRed)
+	fillRect(sc, image.Rect(40, 30, 45, 35), testRed)
+	e, _ := newTestEbitest(t, sc)
+
+	// It was at the bottom right before and now there are
+	// two so the closest to the previous position is used
+	sel := e.found(NewFromImage(newSolidImage(5, 5, testRed)), image.Rect(50, 30, 55, 35), 1)
+	require.True(t, sel.Refresh())
+	assert.Equal(t, image.Rect(40, 30, 45, 35), sel.Rec())
+
+	sel = e.found(NewFromImage(newSolidImage(5, 5, testRed)), image.Rect(0, 0, 5, 5), 1)
+	require.True(t, sel.Refresh())
+	assert.Equal(t, image.Rect(5, 5, 10, 10), sel.Rec())
+
+	// If it's not found the Selector is not changed
+	sel = e.found(NewFromImage(newSolidImage(5, 5, testBlue)), image.Rect(0, 0, 5, 5), 1)
+	assert.False(t, sel.Refresh())
+	assert.Equal(t, image.Rect(0, 0, 5, 5), sel.Rec())
+}
+
+func TestSelectorClickVerified(t *testing.T) {
+	sc := newSolidImage(60, 40, testBackground)
+	fillRect(sc, image.Rect(10, 10, 20, 20), testRed)
+	e, clicks := newTestEbitest(t, sc)
+
+	sel := e.Must(t, newSolidImage(10, 10, testRed))
+	require.NoError(t, sel.ClickVerified())
+	assert.Equal(t, Ball{X: 15, Y: 15}, <-clicks)
+
+	require.NoError(t, sel.ClickAtVerified(TopLeft.Offset(1, 2)))
+	assert.Equal(t, Ball{X: 11, Y: 12}, <-clicks)
+
+	// It's no longer there so it does not click
+	e.game.SetScreen(newSolidImage(60, 40, testBackground))
+	err := sel.ClickVerified()
+	assert.ErrorIs(t, err, ErrStaleSelector)
+	assert.EqualError(t, err, "stale selector: selector not at (10,10)-(20,20)")
+	assert.Empty(t, clicks)
+
+	// The Selectors not returned by the searches are never there
+	assert.ErrorIs(t, NewFromImage(sc).ClickVerified(), ErrStaleSelector)
+	assert.Empty(t, clicks)
+}
+
+func TestMatchThreshold(t *testing.T) {
+	img := newSolidImage(5, 5, testRed)
+	e := &Ebitest{}
+	assert.Equal(t, 1.0, e.matchThreshold(NewFromImage(img)))
+	assert.Equal(t, defaultSSIMThreshold, e.matchThreshold(NewFromImage(img).WithMatcher(MatchSSIM)))
+
+	// The 0 is a valid threshold and not the default one
+	WithMatchThreshold(0)(&e.options)
+	assert.Equal(t, 0.0, e.matchThreshold(NewFromImage(img)))
+	assert.Equal(t, 0.0, e.matchThreshold(NewFromImage(img).WithMatcher(MatchSSIM)))
+	assert.Equal(t, 0.5, e.matchThreshold(NewFromImage(img).WithMatchThreshold(0.5)))
+
+	WithMatchThreshold(0.8)(&e.options)
+	assert.Equal(t, 0.0, e.matchThreshold(NewFromImage(img).WithMatchThreshold(0)))
+	assert.Equal(t, 1.0, e.matchThreshold(NewFromImage(img).WithMatchThreshold(1.5)))
+	assert.Equal(t, 0.0, e.matchThreshold(NewFromImage(img).WithMatchThreshold(-1)))
+
+	// With 0 it matches on any location
+	sc := newSolidImage(10, 10, testBlue)
+	sels, _ := e.findSelectors(sc, NewFromImage(img).WithMatchThreshold(0), findAllSelectors, emptyRec)
+	assert.Len(t, sels, 36)
+	assert.Equal(t, 0.0, sels[0].Score())
+
+	// Also the small ones that would be searched only on the locations of the anchors
+	sc = newSolidImage(10, 10, testBackground)
+	sels, _ = e.findSelectors(sc, NewFromImage(newSolidImage(2, 2, testRed)).WithMatchThreshold(0), findAllSelectors, emptyRec)
+	assert.Len(t, sels, 81)
+}
+
+func TestSelectorDumpImage(t *testing.T) {
+	e := &Ebitest{}
+	sc := newTestScreen(60, 40)
+	rect := image.Rect(10, 10, 30, 25)
+	sub := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(sub, sub.Bounds(), sc, rect.Min, draw.Src)
+
+	// One pixel is a bit different and the other a lot
+	near, far := image.Pt(2, 3), image.Pt(5, 6)
+	for _, d := range []struct {
+		p image.Point
+		v uint8
+	}{{p: near, v: 3}, {p: far, v: 100}} {
+		c := sub.NRGBAAt(d.p.X, d.p.Y)
+		c.R += d.v
+		sub.SetNRGBA(d.p.X, d.p.Y, c)
+	}
+
+	sel := e.found(NewFromImage(sub), rect, 1)
+	di := sel.dumpImage(sc, ColorTolerance{Delta: 5}, defaultAlphaThreshold)
+	assert.Equal(t, sub.At(near.X, near.Y), di.At(near.X, near.Y))
+	assert.Equal(t, mismatchDumpColor, di.At(far.X, far.Y))
+	assert.Equal(t, sub.At(0, 0), di.At(0, 0))
+
+	di = sel.dumpImage(sc, ColorTolerance{}, defaultAlphaThreshold)
+	assert.Equal(t, mismatchDumpColor, di.At(near.X, near.Y))
+	assert.Equal(t, mismatchDumpColor, di.At(far.X, far.Y))
+
+	// The alternative image that matches better is the one used
+	other := newSolidImage(rect.Dx(), rect.Dy(), testRed)
+	sel = e.found(NewFromImage(other), rect, 1)
+	sel.alts = []image.Image{sub}
+	di = sel.dumpImage(sc, ColorTolerance{Delta: 5}, defaultAlphaThreshold)
+	assert.Equal(t, sub.At(near.X, near.Y), di.At(near.X, near.Y))
+	assert.Equal(t, mismatchDumpColor, di.At(far.X, far.Y))
+
+	// The semi-transparent pixels are highlighted if they are compared with the alpha threshold
+	semi := image.NewNRGBA(sub.Bounds())
+	draw.Draw(semi, semi.Bounds(), sub, image.Point{}, draw.Src)
+	c := semi.NRGBAAt(far.X, far.Y)
+	c.A = 128
+	semi.SetNRGBA(far.X, far.Y, c)
+	sel = e.found(NewFromImage(semi), rect, 1)
+	di = sel.dumpImage(sc, ColorTolerance{Delta: 5}, 128)
+	assert.Equal(t, mismatchDumpColor, di.At(far.X, far.Y))
+	assert.Equal(t, semi.At(near.X, near.Y), di.At(near.X, near.Y))
+	di = sel.dumpImage(sc, ColorTolerance{Delta: 5}, defaultAlphaThreshold)
+	assert.Equal(t, semi.At(far.X, far.Y), di.At(far.X, far.Y))
+
+	// If it's not at a position of the screen nothing is highlighted
+	assert.Same(t, sub, NewFromImage(sub).dumpImage(sc, ColorTolerance{}, defaultAlphaThreshold))
+}
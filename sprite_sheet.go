@@ -0,0 +1,307 @@
+package ebitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SpriteSheet is an image with many frames (sprites) that
+// can be used as selectors by their name
+type SpriteSheet struct {
+	img *image.NRGBA
+
+	// frames are the rectangle of each frame on the image
+	// and order the names of them as they were defined
+	frames map[string]image.Rectangle
+	order  []string
+
+	// rotated are the frames that are stored rotated 90° clockwise
+	rotated map[string]bool
+
+	// animations are the frames of each animation
+	animations map[string][]string
+}
+
+// atlas is the JSON descriptor of the Aseprite and TexturePacker formats
+type atlas struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name string `json:"name"`
+			From int    `json:"from"`
+			To   int    `json:"to"`
+		} `json:"frameTags"`
+	} `json:"meta"`
+}
+
+// atlasFrame is a frame of the atlas
+type atlasFrame struct {
+	Filename string `json:"filename"`
+	Frame    struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frame"`
+	Rotated bool `json:"rotated"`
+}
+
+// NewSpriteSheetGrid creates a SpriteSheet slicing img on a grid of frames of w*h, which are
+// named by their index from left-to-right and top-to-bottom ("0", "1", ...). The empty
+// (fully transparent) frames are skipped
+func NewSpriteSheetGrid(img image.Image, w, h int) *SpriteSheet {
+	ss := newSpriteSheet(img)
+	b := ss.img.Bounds()
+	if w <= 0 || h <= 0 {
+		return ss
+	}
+
+	var i int
+	for y := b.Min.Y; y+h <= b.Max.Y; y += h {
+		for x := b.Min.X; x+w <= b.Max.X; x += w {
+			r := image.Rect(x, y, x+w, y+h)
+			if !isEmpty(ss.img.SubImage(r).(*image.NRGBA)) {
+				ss.addFrame(strconv.Itoa(i), r, false)
+			}
+			i++
+		}
+	}
+
+	return ss
+}
+
+// NewSpriteSheetAtlas creates a SpriteSheet from img and the JSON atlas descriptor data with
+// the format of Aseprite or TexturePacker (both 'hash' and 'array'). The frames are named
+// as on the descriptor and the Aseprite tags are the animations
+func NewSpriteSheetAtlas(img image.Image, data []byte) (*SpriteSheet, error) {
+	var a atlas
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to decode the atlas: %w", err)
+	}
+
+	frames, err := a.frames()
+	if err != nil {
+		return nil, err
+	}
+
+	ss := newSpriteSheet(img)
+	for _, f := range frames {
+		r := image.Rect(f.Frame.X, f.Frame.Y, f.Frame.X+f.Frame.W, f.Frame.Y+f.Frame.H)
+		// The rotated frames have the size they have before the rotation
+		if f.Rotated {
+			r = image.Rect(f.Frame.X, f.Frame.Y, f.Frame.X+f.Frame.H, f.Frame.Y+f.Frame.W)
+		}
+		if !r.In(ss.img.Bounds()) {
+			return nil, fmt.Errorf("frame %q at %v is outside of the image %v", f.Filename, r, ss.img.Bounds())
+		}
+		ss.addFrame(f.Filename, r, f.Rotated)
+	}
+
+	for _, t := range a.Meta.FrameTags {
+		if t.From < 0 || t.To >= len(ss.order) || t.From > t.To {
+			return nil, fmt.Errorf("tag %q has the frames %d-%d out of range", t.Name, t.From, t.To)
+		}
+		ss.DefineAnimation(t.Name, ss.order[t.From:t.To+1]...)
+	}
+
+	return ss, nil
+}
+
+// SpriteSheetFromFS creates a SpriteSheet from the JSON atlas descriptor at atlasPath of fsys
+// as on NewSpriteSheetAtlas, the image is the one on the descriptor relative to it
+func SpriteSheetFromFS(fsys fs.FS, atlasPath string) (*SpriteSheet, error) {
+	data, err := fs.ReadFile(fsys, atlasPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", atlasPath, err)
+	}
+
+	var a atlas
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to decode the atlas %q: %w", atlasPath, err)
+	}
+
+	sel, err := FromFS(fsys, path.Join(path.Dir(atlasPath), a.Meta.Image))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSpriteSheetAtlas(sel.img, data)
+}
+
+// SpriteSheetFromFile creates a SpriteSheet from the JSON atlas descriptor at atlasPath
+// as on SpriteSheetFromFS
+func SpriteSheetFromFile(atlasPath string) (*SpriteSheet, error) {
+	return SpriteSheetFromFS(os.DirFS(filepath.Dir(atlasPath)), filepath.Base(atlasPath))
+}
+
+// newSpriteSheet returns an empty SpriteSheet of img
+func newSpriteSheet(img image.Image) *SpriteSheet {
+	return &SpriteSheet{
+		img:        toNRGBA(img),
+		frames:     make(map[string]image.Rectangle),
+		rotated:    make(map[string]bool),
+		animations: make(map[string][]string),
+	}
+}
+
+// frames returns the frames of the atlas in the order they are defined
+// with the name set for the 'hash' format
+func (a atlas) frames() ([]atlasFrame, error) {
+	raw := bytes.TrimSpace(a.Frames)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("the atlas has no frames")
+	}
+
+	if raw[0] == '[' {
+		var frames []atlasFrame
+		if err := json.Unmarshal(raw, &frames); err != nil {
+			return nil, fmt.Errorf("failed to decode the frames: %w", err)
+		}
+		return frames, nil
+	}
+
+	// The order of the 'hash' is the one of the frames, which
+	// is used by the tags, so the keys are read one by one
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("failed to decode the frames: %w", err)
+	}
+	frames := make([]atlasFrame, 0)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the frames: %w", err)
+		}
+		var f atlasFrame
+		if err := dec.Decode(&f); err != nil {
+			return nil, fmt.Errorf("failed to decode the frame %q: %w", t, err)
+		}
+		f.Filename = t.(string)
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// addFrame adds the frame n at r of the image
+func (ss *SpriteSheet) addFrame(n string, r image.Rectangle, rotated bool) {
+	if _, ok := ss.frames[n]; !ok {
+		ss.order = append(ss.order, n)
+	}
+	ss.frames[n] = r
+	ss.rotated[n] = rotated
+}
+
+// DefineAnimation defines the animation n with the frames, so it
+// can be used with Animation. The frames that do not exist are ignored
+func (ss *SpriteSheet) DefineAnimation(n string, frames ...string) *SpriteSheet {
+	ss.animations[n] = frames
+	return ss
+}
+
+// Frames returns the names of all the frames
+func (ss *SpriteSheet) Frames() []string {
+	return append([]string{}, ss.order...)
+}
+
+// Animations returns the names of all the animations sorted
+func (ss *SpriteSheet) Animations() []string {
+	names := make([]string, 0, len(ss.animations))
+	for n := range ss.animations {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Frame returns a Selector of the frame n named as the frame,
+// it returns an error with the available ones if it does not exist
+func (ss *SpriteSheet) Frame(n string) (*Selector, error) {
+	r, ok := ss.frames[n]
+	if !ok {
+		return nil, fmt.Errorf("frame %q not found, the available ones are: %s", n, strings.Join(ss.order, ", "))
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(img, img.Bounds(), ss.img, r.Min, draw.Src)
+
+	var fimg image.Image = img
+	// The rotated frames are stored rotated clockwise
+	if ss.rotated[n] {
+		fimg = transformImage(img, Transform{Angle: -90}, ScaleNearest)
+	}
+
+	return newNamedSelector(fimg, n), nil
+}
+
+// Animation returns a Selector that finds any of the frames of the animation n, which
+// are the ones defined with DefineAnimation (or the Aseprite tags) or if none the frames
+// named n followed by a separator and an index (as "walk" for "walk_0.png", "walk-1" or
+// "walk 2.aseprite"). It returns an error if the animation has no frames
+func (ss *SpriteSheet) Animation(n string) (*Selector, error) {
+	frames, ok := ss.animations[n]
+	if !ok {
+		for _, f := range ss.order {
+			if isAnimationFrame(f, n) {
+				frames = append(frames, f)
+			}
+		}
+	}
+
+	parts := make([]interface{}, 0, len(frames))
+	for _, f := range frames {
+		if fsel, err := ss.Frame(f); err == nil {
+			parts = append(parts, fsel)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("animation %q not found, the available ones are: %s", n, strings.Join(ss.Animations(), ", "))
+	}
+
+	sel := AnyOf(parts...)
+	sel.name = n
+	return sel, nil
+}
+
+// isAnimationFrame checks if the frame f is named as a frame of the animation n,
+// which is n followed by a separator ('_', '-', '.' or ' '), the index of the
+// frame and optionally an extension, as "walk_0" or "walk 1.aseprite"
+func isAnimationFrame(f, n string) bool {
+	rest, ok := strings.CutPrefix(f, n)
+	if !ok || rest == "" || !strings.ContainsRune("_-. ", rune(rest[0])) {
+		return false
+	}
+	idx, _, _ := strings.Cut(rest[1:], ".")
+	if idx == "" {
+		return false
+	}
+	for _, r := range idx {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmpty checks if all the pixels of img are fully transparent
+func isEmpty(img *image.NRGBA) bool {
+	b := img.Bounds()
+	for x := range b.Dx() {
+		for y := range b.Dy() {
+			if nrgbaAt(img, x, y).A != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
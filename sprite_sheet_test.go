@@ -0,0 +1,130 @@
+package ebitest
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSheet returns a sheet of 3x2 frames of 4x4 in which each
+// frame has the top-left pixel of a different color and the last one is empty
+func newTestSheet() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 12, 8))
+	for i := range 5 {
+		img.SetNRGBA((i%3)*4, (i/3)*4, color.NRGBA{uint8(i * 10), 0, 0, 255})
+	}
+	return img
+}
+
+func TestNewSpriteSheetGrid(t *testing.T) {
+	ss := NewSpriteSheetGrid(newTestSheet(), 4, 4)
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, ss.Frames())
+
+	f, err := ss.Frame("4")
+	require.NoError(t, err)
+	assert.Equal(t, "4", f.Name())
+	assert.Equal(t, image.Rect(0, 0, 4, 4), f.Image().Bounds())
+	assert.Equal(t, color.NRGBA{40, 0, 0, 255}, f.Image().(*image.NRGBA).NRGBAAt(0, 0))
+
+	_, err = ss.Frame("5")
+	assert.EqualError(t, err, `frame "5" not found, the available ones are: 0, 1, 2, 3, 4`)
+
+	a, err := ss.DefineAnimation("idle", "0", "1", "9").Animation("idle")
+	require.NoError(t, err)
+	assert.Equal(t, "idle", a.Name())
+	assert.Len(t, a.finder.(*composite).parts, 2)
+
+	_, err = ss.Animation("run")
+	assert.EqualError(t, err, `animation "run" not found, the available ones are: idle`)
+}
+
+func TestNewSpriteSheetAtlas(t *testing.T) {
+	sheet := newTestSheet()
+	tcs := []struct {
+		name string
+		data string
+	}{
+		{name: "AsepriteHash", data: `{
+			"frames": {
+				"walk 1.aseprite": {"frame": {"x": 4, "y": 0, "w": 4, "h": 4}},
+				"walk 0.aseprite": {"frame": {"x": 0, "y": 0, "w": 4, "h": 4}},
+				"jump 0.aseprite": {"frame": {"x": 8, "y": 0, "w": 4, "h": 4}}
+			},
+			"meta": {"image": "sheet.png", "frameTags": [{"name": "walk", "from": 0, "to": 1, "direction": "forward"}]}
+		}`},
+		{name: "TexturePackerArray", data: `{
+			"frames": [
+				{"filename": "walk 1.aseprite", "frame": {"x": 4, "y": 0, "w": 4, "h": 4}, "rotated": false},
+				{"filename": "walk 0.aseprite", "frame": {"x": 0, "y": 0, "w": 4, "h": 4}, "rotated": false},
+				{"filename": "jump 0.aseprite", "frame": {"x": 8, "y": 0, "w": 4, "h": 4}, "rotated": false}
+			],
+			"meta": {"image": "sheet.png"}
+		}`},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ss, err := NewSpriteSheetAtlas(sheet, []byte(tc.data))
+			require.NoError(t, err)
+			assert.Equal(t, []string{"walk 1.aseprite", "walk 0.aseprite", "jump 0.aseprite"}, ss.Frames())
+			f, err := ss.Frame("jump 0.aseprite")
+			require.NoError(t, err)
+			assert.Equal(t, color.NRGBA{20, 0, 0, 255}, f.Image().(*image.NRGBA).NRGBAAt(0, 0))
+
+			// With the tag or by the name and index of the frames
+			a, err := ss.Animation("walk")
+			require.NoError(t, err)
+			assert.Len(t, a.finder.(*composite).parts, 2)
+		})
+	}
+
+	t.Run("Rotated", func(t *testing.T) {
+		ss, err := NewSpriteSheetAtlas(sheet, []byte(`{"frames": [
+			{"filename": "r", "frame": {"x": 0, "y": 0, "w": 4, "h": 8}, "rotated": true}
+		]}`))
+		require.NoError(t, err)
+		f, err := ss.Frame("r")
+		require.NoError(t, err)
+		img := f.Image().(*image.NRGBA)
+		require.Equal(t, image.Rect(0, 0, 4, 8), img.Bounds())
+		// The top-left pixel of the sheet is the bottom-left once rotated back
+		assert.Equal(t, color.NRGBA{0, 0, 0, 255}, img.NRGBAAt(0, 7))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := NewSpriteSheetAtlas(sheet, []byte(`{"frames": [{"filename": "a", "frame": {"x": 10, "y": 0, "w": 4, "h": 4}}]}`))
+		assert.ErrorContains(t, err, "outside of the image")
+		_, err = NewSpriteSheetAtlas(sheet, []byte(`{"frames": {"a": {"frame": {"x": 0, "y": 0, "w": 4, "h": 4}}}, "meta": {"frameTags": [{"name": "t", "from": 0, "to": 3}]}}`))
+		assert.ErrorContains(t, err, "out of range")
+		_, err = NewSpriteSheetAtlas(sheet, []byte(`{}`))
+		assert.ErrorContains(t, err, "no frames")
+	})
+}
+
+func TestIsAnimationFrame(t *testing.T) {
+	for _, f := range []string{"walk_0", "walk-1", "walk.2", "walk 3.aseprite", "walk_10.png"} {
+		assert.True(t, isAnimationFrame(f, "walk"), f)
+	}
+	for _, f := range []string{"walk", "walking_0", "walk_", "walk_a", "walk_0a.png", "walk_-1", "run_0"} {
+		assert.False(t, isAnimationFrame(f, "walk"), f)
+	}
+}
+
+func TestSpriteSheetFromFS(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, newTestSheet()))
+	fsys := fstest.MapFS{
+		"assets/sheet.png":  {Data: buf.Bytes()},
+		"assets/sheet.json": {Data: []byte(`{"frames": {"a": {"frame": {"x": 0, "y": 0, "w": 4, "h": 4}}}, "meta": {"image": "sheet.png"}}`)},
+	}
+
+	ss, err := SpriteSheetFromFS(fsys, "assets/sheet.json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, ss.Frames())
+}
@@ -0,0 +1,208 @@
+package ebitest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Matcher is the way in which a Selector is compared with the screen
+type Matcher int
+
+const (
+	// MatchPixels compares each pixel of the Selector with the
+	// screen and the score is the fraction of them that are equal
+	MatchPixels Matcher = iota
+
+	// MatchSSIM compares the structural similarity (SSIM) of windows
+	// of the Selector and the screen by their luminance, contrast and
+	// structure, and the score is the mean of all of them (0-1) with the
+	// windows that have the structure inverted as 0. It's useful when the
+	// screen has effects (shaders, bloom, dithering) that change the
+	// pixels but not how it looks
+	MatchSSIM
+)
+
+const (
+	// defaultSSIMThreshold is the minimum score used
+	// with MatchSSIM if no other is defined
+	defaultSSIMThreshold = 0.9
+
+	// ssimWindow is the size of the windows that are compared
+	ssimWindow = 8
+
+	// ssimC1 and ssimC2 are the constants that stabilize
+	// the division with weak denominators
+	ssimC1 = (0.01 * 255) * (0.01 * 255)
+	ssimC2 = (0.03 * 255) * (0.03 * 255)
+)
+
+// ssimBlock is a window of a template with the pixels that
+// participate on it and their luminance statistics
+type ssimBlock struct {
+	pixels   []tpixel
+	lums     []float64
+	mean     float64
+	variance float64
+}
+
+// newSSIMTemplate returns a template from i that is compared with the SSIM
+// using only the pixels with at least an alpha of alphaThreshold
+func newSSIMTemplate(i image.Image, alphaThreshold uint8) *template {
+	t := newTemplate(i, alphaThreshold)
+
+	idx := make(map[image.Point]int)
+	for _, p := range t.pixels {
+		k := image.Pt(p.x/ssimWindow, p.y/ssimWindow)
+		bi, ok := idx[k]
+		if !ok {
+			bi = len(t.ssim)
+			idx[k] = bi
+			t.ssim = append(t.ssim, ssimBlock{})
+		}
+		t.ssim[bi].pixels = append(t.ssim[bi].pixels, p)
+		t.ssim[bi].lums = append(t.ssim[bi].lums, luminance(p.c))
+	}
+
+	for bi := range t.ssim {
+		b := &t.ssim[bi]
+		n := float64(len(b.lums))
+		for _, l := range b.lums {
+			b.mean += l
+		}
+		b.mean /= n
+		for _, l := range b.lums {
+			b.variance += (l - b.mean) * (l - b.mean)
+		}
+		b.variance /= n
+	}
+
+	return t
+}
+
+// luminance returns the luma (0-255) of c ignoring the alpha
+func luminance(c color.NRGBA) float64 {
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+// ssimScoreAt returns the SSIM of t with sc at the x, y of the screen, which is the
+// mean of the SSIM of the blocks weighted by the number of pixels. If the score can
+// not reach minScore it stops and returns false
+func (t *template) ssimScoreAt(sc *image.NRGBA, x, y int, minScore float64) (float64, bool) {
+	total := float64(len(t.pixels))
+	remaining := total
+	var sum float64
+	for _, b := range t.ssim {
+		n := float64(len(b.pixels))
+
+		var mean float64
+		for _, p := range b.pixels {
+			mean += luminance(nrgbaAt(sc, x+p.x, y+p.y))
+		}
+		mean /= n
+
+		var variance, covariance float64
+		for i, p := range b.pixels {
+			d := luminance(nrgbaAt(sc, x+p.x, y+p.y)) - mean
+			variance += d * d
+			covariance += d * (b.lums[i] - b.mean)
+		}
+		variance /= n
+		covariance /= n
+
+		s := ((2*b.mean*mean + ssimC1) * (2*covariance + ssimC2)) /
+			((b.mean*b.mean + mean*mean + ssimC1) * (b.variance + variance + ssimC2))
+		// The SSIM is negative when the structure is inverted, which is
+		// as different as it can be for the thresholds that are 0-1
+		s = max(0, s)
+
+		// The SSIM of a block is at most 1 so if with the rest of
+		// blocks it can not reach minScore it's not a match
+		sum += s * n
+		remaining -= n
+		if (sum+remaining)/total < minScore {
+			return 0, false
+		}
+	}
+	score := sum / total
+	return score, score >= minScore
+}
+
+// ShouldMatchScreen checks if the whole screen matches selector(s), which has to be
+// of the size of the screen, like a screenshot of a previous run. The score is
+// returned on the Selector and with MatchSSIM it tolerates effects over the screen
+// s can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (e *Ebitest) ShouldMatchScreen(t *testing.T, s interface{}) (*Selector, bool) {
+	t.Helper()
+	e.PingPong.Ping()
+	sc := e.game.GetScreen()
+
+	sel, ok := e.matchScreen(sc, s)
+	if !ok {
+		assert.Fail(t, e.screenNotMatchedMessage(sc, sel))
+		return nil, false
+	}
+
+	return sel, true
+}
+
+// MustMatchScreen checks if the whole screen matches selector(s) as ShouldMatchScreen.
+// If it does not match it'll fail the test
+// s can be a: 'string', '*ebitest.TextSelector', 'image.Image', '*ebiten.Image' and '*ebitest.Selector'
+func (e *Ebitest) MustMatchScreen(t *testing.T, s interface{}) *Selector {
+	t.Helper()
+	e.PingPong.Ping()
+	sc := e.game.GetScreen()
+
+	sel, ok := e.matchScreen(sc, s)
+	if !ok {
+		require.Fail(t, e.screenNotMatchedMessage(sc, sel))
+		return nil
+	}
+
+	return sel
+}
+
+// matchScreen compares ss with sc at the origin and returns it
+// with the score and if it reaches the threshold
+func (e *Ebitest) matchScreen(sc image.Image, ss interface{}) (*Selector, bool) {
+	bsel := e.getSelector(ss)
+	region := screenRegion(sc, emptyRec)
+	rb := region.Bounds()
+
+	img := bsel.matchImages()[0]
+	if !img.Bounds().Size().Eq(rb.Size()) {
+		return bsel, false
+	}
+
+	tmpl := e.template(bsel, variant{img: img, scale: 1})
+	// As all the pixels are compared the score is
+	// calculated fully even if it does not match
+	m, ok := tmpl.matchAt(region, 0, 0, e.colorTolerance(bsel), tmpl.total())
+	if !ok {
+		return bsel, false
+	}
+
+	sel := e.found(bsel, rb, m.score)
+	sel.scale = 1
+	return sel, m.score >= e.matchThreshold(bsel)
+}
+
+// screenNotMatchedMessage returns the failure message for when sel does not match sc
+func (e *Ebitest) screenNotMatchedMessage(sc image.Image, sel *Selector) string {
+	msg := "screen does not match"
+	if s := sel.img.Bounds().Size(); !s.Eq(sc.Bounds().Size()) {
+		msg += fmt.Sprintf(": selector size %v and screen size %v", s, sc.Bounds().Size())
+	} else {
+		msg += fmt.Sprintf(": score %.3f", sel.score)
+	}
+	if e.options.dumpErrorImages {
+		p := dumpErrorImages(sc, sel, emptyRec, e.colorTolerance(sel), e.alphaThreshold(sel))
+		msg += "\nimage at: " + p
+	}
+	return msg
+}
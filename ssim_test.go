@@ -0,0 +1,78 @@
+package ebitest
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSIMTemplateSearch(t *testing.T) {
+	sc := newTestScreen(160, 120)
+	rect := image.Rect(30, 40, 70, 70)
+	sub := newTestSelector(sc, rect)
+
+	// Add noise to the screen as a dithering would do
+	r := rand.New(rand.NewSource(1))
+	noisy := image.NewNRGBA(sc.Bounds())
+	for x := range 160 {
+		for y := range 120 {
+			c := sc.NRGBAAt(x, y)
+			d := func(v uint8) uint8 { return uint8(max(0, min(255, int(v)+r.Intn(9)-4))) }
+			noisy.SetNRGBA(x, y, color.NRGBA{d(c.R), d(c.G), d(c.B), 255})
+		}
+	}
+
+	ms := newTemplate(sub, 255).search(noisy, searchOptions{threshold: 1})
+	assert.Empty(t, ms)
+
+	tmpl := newSSIMTemplate(sub, 255)
+	ms = tmpl.search(noisy, searchOptions{threshold: defaultSSIMThreshold})
+	require.Len(t, ms, 1)
+	assert.Equal(t, rect, ms[0].rect)
+	assert.Less(t, ms[0].score, 1.0)
+
+	ms = tmpl.search(sc, searchOptions{threshold: 1})
+	require.Len(t, ms, 1)
+	assert.Equal(t, rect, ms[0].rect)
+	assert.InDelta(t, 1, ms[0].score, 0.0001)
+}
+
+func TestSSIMScoreAt(t *testing.T) {
+	sub := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	inv := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for x := range 16 {
+		for y := range 16 {
+			v := uint8((x + y) * 8)
+			sub.SetNRGBA(x, y, color.NRGBA{v, v, v, 255})
+			inv.SetNRGBA(x, y, color.NRGBA{255 - v, 255 - v, 255 - v, 255})
+		}
+	}
+	tmpl := newSSIMTemplate(sub, 255)
+	require.Len(t, tmpl.ssim, 4)
+
+	s, ok := tmpl.ssimScoreAt(sub, 0, 0, 0.9)
+	assert.True(t, ok)
+	assert.InDelta(t, 1, s, 0.0001)
+
+	// The inverted structure is not similar and
+	// its score is 0 instead of a negative one
+	_, ok = tmpl.ssimScoreAt(inv, 0, 0, 0.9)
+	assert.False(t, ok)
+	s, ok = tmpl.ssimScoreAt(inv, 0, 0, 0)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, s)
+
+	e := &Ebitest{}
+	sel, ok := e.matchScreen(inv, NewFromImage(sub).WithMatcher(MatchSSIM))
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, sel.Score())
+	assert.Equal(t, "screen does not match: score 0.000", e.screenNotMatchedMessage(inv, sel))
+
+	sel, ok = e.matchScreen(sub, NewFromImage(sub).WithMatcher(MatchSSIM))
+	assert.True(t, ok)
+	assert.InDelta(t, 1, sel.Score(), 0.0001)
+}
@@ -15,8 +15,17 @@ import (
 	"golang.org/x/image/font/gofont/goregular"
 )
 
+// The texts drawn by the Game out of the UI
+const (
+	SubPixelText  = "Sub Pixel"
+	MultiLineText = "Line 1\nLine 2"
+
+	MultiLineSpacing = 28
+)
+
 type Game struct {
-	ui *ebitenui.UI
+	ui   *ebitenui.UI
+	face text.Face
 
 	Clicked       bool
 	ClickedShiftI bool
@@ -94,7 +103,8 @@ func NewGame() *Game {
 	}
 
 	game := Game{
-		ui: &ui,
+		ui:   &ui,
+		face: face,
 	}
 
 	return &game
@@ -120,6 +130,19 @@ func (g *Game) Update() error {
 
 func (g *Game) Draw(screen *ebiten.Image) {
 	g.ui.Draw(screen)
+
+	// A text at a fractional position
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(10.5, 10.25)
+	op.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, SubPixelText, g.face, op)
+
+	// A text with multiple lines
+	op = &text.DrawOptions{}
+	op.GeoM.Translate(10, 60)
+	op.LineSpacing = MultiLineSpacing
+	op.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, MultiLineText, g.face, op)
 }
 
 func loadButtonImage() (*widget.ButtonImage, error) {
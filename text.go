@@ -0,0 +1,276 @@
+package ebitest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// TextLayout is how a text is laid out when drawn by the game
+type TextLayout struct {
+	// LayoutOptions are the same used by the game on the text.DrawOptions
+	text.LayoutOptions
+
+	// FaceLineSpacing uses the line spacing of the Face (ascent, descent
+	// and line gap) instead of the LineSpacing of the LayoutOptions
+	FaceLineSpacing bool
+
+	// WrapWidth is the maximum width of the lines, the words that do
+	// not fit are moved to the next line. If 0 the lines are not wrapped
+	WrapWidth float64
+}
+
+// TextSelector is a text to search for with its own options,
+// the ones not set use the default ones set on Run
+type TextSelector struct {
+	txt       string
+	face      text.Face
+	color     color.Color
+	layout    TextLayout
+	anyColor  bool
+	subPixels int
+}
+
+type textOptionsFn func(*TextSelector)
+
+// Text returns a TextSelector of txt with the opts, it can be used
+// as a selector on the assertions the same as a 'string'
+func Text(txt string, opts ...textOptionsFn) *TextSelector {
+	ts := &TextSelector{
+		txt: txt,
+	}
+	for _, ofn := range opts {
+		ofn(ts)
+	}
+	return ts
+}
+
+// Face sets the Face of the text instead of the default ones
+func Face(f text.Face) textOptionsFn {
+	return func(ts *TextSelector) {
+		ts.face = f
+	}
+}
+
+// Color sets the Color of the text instead of the default one
+func Color(c color.Color) textOptionsFn {
+	return func(ts *TextSelector) {
+		ts.color = c
+	}
+}
+
+// Layout sets how the text is laid out
+func Layout(tl TextLayout) textOptionsFn {
+	return func(ts *TextSelector) {
+		ts.layout = tl
+	}
+}
+
+// AnyColor makes the text to match regardless of the color, like NewFromTextShape
+func AnyColor() textOptionsFn {
+	return func(ts *TextSelector) {
+		ts.anyColor = true
+	}
+}
+
+// SubPixels renders the text at n*n sub-pixel offsets, like NewFromTextSubPixels
+func SubPixels(n int) textOptionsFn {
+	return func(ts *TextSelector) {
+		ts.subPixels = n
+	}
+}
+
+// resolvedText are the options used to render a TextSelector
+type resolvedText struct {
+	faces     []text.Face
+	color     color.Color
+	anyColor  bool
+	subPixels int
+}
+
+// textSelector returns the Selector of ts using the default
+// options for the ones not set
+func (e *Ebitest) textSelector(ts *TextSelector) *Selector {
+	rt := e.resolveText(ts)
+	s := newFromTextVariants(ts.txt, rt.faces, rt.color, ts.layout, rt.subPixels)
+	if rt.anyColor {
+		s.WithAnyColor()
+	}
+	return s
+}
+
+// resolveText returns the options of ts with the default ones for the ones not set
+func (e *Ebitest) resolveText(ts *TextSelector) resolvedText {
+	faces := []text.Face{ts.face}
+	if ts.face == nil {
+		faces = e.faces()
+	}
+	if len(faces) == 0 {
+		panic(fmt.Sprintf("No Face for the text %q, set one with 'ebitest.WithFace' or 'ebitest.Face'", ts.txt))
+	}
+
+	c := ts.color
+	if c == nil {
+		c = e.options.color
+	}
+
+	anyColor := ts.anyColor || e.options.anyTextColor
+	if anyColor {
+		c = color.White
+	}
+
+	n := ts.subPixels
+	if n == 0 {
+		n = e.options.textSubPixels
+	}
+
+	return resolvedText{
+		faces:     faces,
+		color:     c,
+		anyColor:  anyColor,
+		subPixels: n,
+	}
+}
+
+// faces returns the default Faces
+func (e *Ebitest) faces() []text.Face {
+	faces := make([]text.Face, 0, len(e.options.faces)+1)
+	if e.options.face != nil {
+		faces = append(faces, e.options.face)
+	}
+	return append(faces, e.options.faces...)
+}
+
+// NewFromTextLayout creates a new Selector from a txt laid out with tl,
+// so it can have multiple lines, alignments and be wrapped
+func NewFromTextLayout(txt string, f text.Face, c color.Color, tl TextLayout) *Selector {
+	return NewFromImage(renderText(txt, f, c, tl, 0, 0))
+}
+
+// NewFromTextSubPixels creates a new Selector from a txt rendered at n*n sub-pixel
+// offsets (k/n for k < n, horizontally and vertically) that are matched as one
+// Selector, so it's found even when the game draws the text at a fractional position
+func NewFromTextSubPixels(txt string, f text.Face, c color.Color, n int) *Selector {
+	return newFromTextVariants(txt, []text.Face{f}, c, TextLayout{}, n)
+}
+
+// newFromTextVariants creates a new Selector from a txt laid out with tl, rendered
+// with all the Faces fs and at n*n sub-pixel offsets that are matched as one
+func newFromTextVariants(txt string, fs []text.Face, c color.Color, tl TextLayout, n int) *Selector {
+	imgs := make([]image.Image, 0, len(fs)*n*n)
+	for _, f := range fs {
+		for _, o := range subPixelOffsets(n) {
+			imgs = append(imgs, toNRGBA(renderText(txt, f, c, tl, o.x, o.y)))
+		}
+	}
+
+	s := NewFromImage(imgs[0])
+	s.alts = imgs[1:]
+	return s
+}
+
+// offset is a sub-pixel offset
+type offset struct {
+	x, y float64
+}
+
+// subPixelOffsets returns the n*n offsets of k/n for k < n
+func subPixelOffsets(n int) []offset {
+	n = max(1, n)
+	offsets := make([]offset, 0, n*n)
+	for i := range n {
+		for j := range n {
+			offsets = append(offsets, offset{
+				x: float64(i) / float64(n),
+				y: float64(j) / float64(n),
+			})
+		}
+	}
+	return offsets
+}
+
+// renderText returns an image with the txt laid out with tl and drawn with
+// the Face f and color c at the (sub-pixel) offset ox, oy
+func renderText(txt string, f text.Face, c color.Color, tl TextLayout, ox, oy float64) image.Image {
+	txt, top, rec := textLayout(txt, f, tl, ox, oy)
+	top.ColorScale.ScaleWithColor(c)
+
+	imgp := image.NewPaletted(rec, color.Palette{color.Transparent})
+	img := ebiten.NewImageFromImage(imgp)
+
+	text.Draw(img, txt, f, top)
+
+	return ebitenImageToImage(img)
+}
+
+// textLayout returns how the txt laid out with tl is drawn with the Face f at the
+// (sub-pixel) offset ox, oy: the txt with the wrapped lines, the options to draw
+// it and the rectangle of the image in which it fits
+func textLayout(txt string, f text.Face, tl TextLayout, ox, oy float64) (string, *text.DrawOptions, image.Rectangle) {
+	if tl.WrapWidth > 0 {
+		txt = wrapText(txt, f, tl.WrapWidth)
+	}
+	if tl.FaceLineSpacing {
+		m := f.Metrics()
+		tl.LineSpacing = m.HAscent + m.HDescent + m.HLineGap
+	}
+
+	top := &text.DrawOptions{}
+	top.LayoutOptions = tl.LayoutOptions
+
+	x, y := text.Measure(txt, f, tl.LineSpacing)
+
+	// The alignments draw the text around the origin so
+	// it's moved to be inside of the image
+	top.GeoM.Translate(alignOffset(tl.PrimaryAlign, x), alignOffset(tl.SecondaryAlign, y))
+	top.GeoM.Translate(ox, oy)
+
+	rec := image.Rect(0, 0, int(x), int(y))
+	if ox != 0 || oy != 0 {
+		// The offset can move the glyphs to the next pixel
+		rec.Max = image.Pt(int(math.Ceil(x+ox)), int(math.Ceil(y+oy)))
+	}
+
+	return txt, top, rec
+}
+
+// alignOffset returns how much the text has to be moved with the
+// alignment a so it starts at 0 when its size is s
+func alignOffset(a text.Align, s float64) float64 {
+	switch a {
+	case text.AlignCenter:
+		return s / 2
+	case text.AlignEnd:
+		return s
+	default:
+		return 0
+	}
+}
+
+// wrapText splits the lines of txt that are wider than w with the
+// Face f on multiple lines. The words wider than w are not split
+func wrapText(txt string, f text.Face, w float64) string {
+	lines := make([]string, 0)
+	for _, l := range strings.Split(txt, "\n") {
+		var line string
+		for _, word := range strings.Fields(l) {
+			if line == "" {
+				line = word
+				continue
+			}
+			if text.Advance(line+" "+word, f) > w {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line += " " + word
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
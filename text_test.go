@@ -0,0 +1,150 @@
+package ebitest
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/ebitest/testdata"
+)
+
+func TestSubPixelOffsets(t *testing.T) {
+	assert.Equal(t, []offset{{0, 0}}, subPixelOffsets(0))
+	assert.Equal(t, []offset{{0, 0}}, subPixelOffsets(1))
+	assert.Equal(t, []offset{{0, 0}, {0, 0.5}, {0.5, 0}, {0.5, 0.5}}, subPixelOffsets(2))
+	assert.Len(t, subPixelOffsets(4), 16)
+}
+
+func TestTextLayoutSubPixels(t *testing.T) {
+	face, err := testdata.LoadFont(20)
+	require.NoError(t, err)
+
+	x, y := text.Measure("Play", face, 0)
+	_, top, rec := textLayout("Play", face, TextLayout{}, 0, 0)
+	assert.Equal(t, image.Rect(0, 0, int(x), int(y)), rec)
+	assert.Equal(t, 0.0, top.GeoM.Element(0, 2))
+
+	// The image grows so the glyphs moved to the next pixel fit
+	_, top, rec = textLayout("Play", face, TextLayout{}, 0.5, 0.25)
+	assert.Equal(t, image.Rect(0, 0, int(math.Ceil(x+0.5)), int(math.Ceil(y+0.25))), rec)
+	assert.Equal(t, 0.5, top.GeoM.Element(0, 2))
+	assert.Equal(t, 0.25, top.GeoM.Element(1, 2))
+}
+
+func TestTextLayout(t *testing.T) {
+	face, err := testdata.LoadFont(20)
+	require.NoError(t, err)
+	m := face.Metrics()
+	lines := "Line 1\nLine 2"
+
+	t.Run("LineSpacing", func(t *testing.T) {
+		txt, top, rec := textLayout(lines, face, TextLayout{LayoutOptions: text.LayoutOptions{LineSpacing: 30}}, 0, 0)
+		x, y := text.Measure(lines, face, 30)
+		assert.Equal(t, lines, txt)
+		assert.Equal(t, 30.0, top.LineSpacing)
+		assert.Equal(t, image.Rect(0, 0, int(x), int(y)), rec)
+	})
+	t.Run("ZeroLineSpacing", func(t *testing.T) {
+		// The lines are drawn one over the other as the game would
+		_, top, rec := textLayout(lines, face, TextLayout{}, 0, 0)
+		_, y := text.Measure("Line 1", face, 0)
+		assert.Equal(t, 0.0, top.LineSpacing)
+		assert.Equal(t, int(y), rec.Dy())
+	})
+	t.Run("FaceLineSpacing", func(t *testing.T) {
+		_, top, rec := textLayout(lines, face, TextLayout{FaceLineSpacing: true}, 0, 0)
+		ls := m.HAscent + m.HDescent + m.HLineGap
+		_, y := text.Measure(lines, face, ls)
+		assert.Equal(t, ls, top.LineSpacing)
+		assert.Equal(t, int(y), rec.Dy())
+	})
+	t.Run("Align", func(t *testing.T) {
+		_, top, rec := textLayout(lines, face, TextLayout{
+			LayoutOptions: text.LayoutOptions{
+				LineSpacing:    30,
+				PrimaryAlign:   text.AlignCenter,
+				SecondaryAlign: text.AlignEnd,
+			},
+		}, 0, 0)
+		x, y := text.Measure(lines, face, 30)
+		assert.Equal(t, image.Rect(0, 0, int(x), int(y)), rec)
+		assert.Equal(t, x/2, top.GeoM.Element(0, 2))
+		assert.Equal(t, y, top.GeoM.Element(1, 2))
+	})
+	t.Run("Wrap", func(t *testing.T) {
+		w := text.Advance("Hello brave", face) + 1
+		txt, _, rec := textLayout("Hello brave new world", face, TextLayout{
+			LayoutOptions: text.LayoutOptions{LineSpacing: 30},
+			WrapWidth:     w,
+		}, 0, 0)
+		assert.Equal(t, "Hello brave\nnew world", txt)
+		assert.LessOrEqual(t, float64(rec.Dx()), w)
+		assert.Equal(t, "Hello\nbrave\nnew\nworld", wrapText("Hello brave new world", face, 1))
+	})
+}
+
+func TestResolveText(t *testing.T) {
+	small, err := testdata.LoadFont(14)
+	require.NoError(t, err)
+	big, err := testdata.LoadFont(32)
+	require.NoError(t, err)
+	other, err := testdata.LoadFont(20)
+	require.NoError(t, err)
+	yellow := color.NRGBA{255, 255, 0, 255}
+
+	e := &Ebitest{}
+	WithFace(small)(&e.options)
+	WithFaces(other)(&e.options)
+	WithColor(color.White)(&e.options)
+	WithTextSubPixels(2)(&e.options)
+
+	tcs := []struct {
+		name string
+		ts   *TextSelector
+		exp  resolvedText
+	}{
+		{
+			name: "Default",
+			ts:   Text("Play"),
+			exp:  resolvedText{faces: []text.Face{small, other}, color: color.White, subPixels: 2},
+		},
+		{
+			name: "Face",
+			ts:   Text("Play", Face(big)),
+			exp:  resolvedText{faces: []text.Face{big}, color: color.White, subPixels: 2},
+		},
+		{
+			name: "Color",
+			ts:   Text("Play", Color(yellow)),
+			exp:  resolvedText{faces: []text.Face{small, other}, color: yellow, subPixels: 2},
+		},
+		{
+			name: "All",
+			ts:   Text("Play", Face(big), Color(yellow), SubPixels(4)),
+			exp:  resolvedText{faces: []text.Face{big}, color: yellow, subPixels: 4},
+		},
+		{
+			name: "AnyColor",
+			ts:   Text("Play", Color(yellow), AnyColor()),
+			exp:  resolvedText{faces: []text.Face{small, other}, color: color.White, anyColor: true, subPixels: 2},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, e.resolveText(tc.ts))
+		})
+	}
+
+	// The per-call options do not change the default ones
+	assert.Equal(t, []text.Face{small, other}, e.faces())
+	assert.Equal(t, color.White, e.options.color)
+
+	assert.PanicsWithValue(t, `No Face for the text "Play", set one with 'ebitest.WithFace' or 'ebitest.Face'`, func() {
+		(&Ebitest{}).resolveText(Text("Play"))
+	})
+}
@@ -0,0 +1,137 @@
+package ebitest
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// Transform is a rotation and flip with which a Selector matched the screen.
+// The flips are applied before the rotation, as it's done with the GeoM
+type Transform struct {
+	// Angle is the clockwise rotation in degrees
+	Angle float64
+
+	// FlipH is a horizontal flip (mirrored left to right)
+	FlipH bool
+
+	// FlipV is a vertical flip (mirrored top to bottom)
+	FlipV bool
+}
+
+// isIdentity checks if t does not change the image
+func (t Transform) isIdentity() bool {
+	return normalizeAngle(t.Angle) == 0 && !t.FlipH && !t.FlipV
+}
+
+// String returns a human readable version of the Transform
+func (t Transform) String() string {
+	parts := make([]string, 0, 3)
+	if a := normalizeAngle(t.Angle); a != 0 {
+		parts = append(parts, fmt.Sprintf("rotated %v°", a))
+	}
+	if t.FlipH {
+		parts = append(parts, "flipped horizontally")
+	}
+	if t.FlipV {
+		parts = append(parts, "flipped vertically")
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// transforms returns all the transforms in which sel has to be searched
+// starting with the identity
+func (sel *Selector) transforms() []Transform {
+	angles := append([]float64{0}, sel.rotations...)
+	flips := []Transform{{}}
+	if sel.flipH {
+		flips = append(flips, Transform{FlipH: true})
+	}
+	if sel.flipV {
+		flips = append(flips, Transform{FlipV: true})
+	}
+	if sel.flipH && sel.flipV {
+		flips = append(flips, Transform{FlipH: true, FlipV: true})
+	}
+
+	trs := make([]Transform, 0, len(angles)*len(flips))
+	seen := make(map[Transform]struct{})
+	for _, a := range angles {
+		for _, f := range flips {
+			t := Transform{Angle: normalizeAngle(a), FlipH: f.FlipH, FlipV: f.FlipV}
+			if _, ok := seen[t]; ok {
+				continue
+			}
+			seen[t] = struct{}{}
+			trs = append(trs, t)
+		}
+	}
+	return trs
+}
+
+// normalizeAngle returns a on the range [0, 360)
+func normalizeAngle(a float64) float64 {
+	a = math.Mod(a, 360)
+	if a < 0 {
+		a += 360
+	}
+	return a
+}
+
+// transformImage returns i flipped and rotated as defined by t. The right
+// angles are exact and the rest are interpolated with the filter f, leaving
+// the corners of the new image transparent so they are not compared
+func transformImage(i image.Image, t Transform, f ScaleFilter) image.Image {
+	if t.isIdentity() {
+		return i
+	}
+
+	a := normalizeAngle(t.Angle)
+	rad := a * math.Pi / 180
+	sin, cos := math.Sin(rad), math.Cos(rad)
+	right := math.Mod(a, 90) == 0
+	if right {
+		sin, cos = math.Round(sin), math.Round(cos)
+	}
+
+	b := i.Bounds()
+	sw, sh := float64(b.Dx()), float64(b.Dy())
+	w := int(math.Round(math.Abs(sw*cos) + math.Abs(sh*sin)))
+	h := int(math.Round(math.Abs(sw*sin) + math.Abs(sh*cos)))
+	img := image.NewNRGBA(image.Rect(0, 0, max(1, w), max(1, h)))
+
+	fx, fy := 1.0, 1.0
+	if t.FlipH {
+		fx = -1
+	}
+	if t.FlipV {
+		fy = -1
+	}
+
+	// Maps the source pixels to the destination by moving the center of the
+	// source to the origin, flipping, rotating and moving it to the center
+	// of the destination
+	cx, cy := float64(b.Min.X)+sw/2, float64(b.Min.Y)+sh/2
+	dx, dy := float64(img.Bounds().Dx())/2, float64(img.Bounds().Dy())/2
+	m := f64.Aff3{
+		cos * fx, -sin * fy, 0,
+		sin * fx, cos * fy, 0,
+	}
+	m[2] = dx - (m[0]*cx + m[1]*cy)
+	m[5] = dy - (m[3]*cx + m[4]*cy)
+
+	var interp draw.Interpolator = draw.NearestNeighbor
+	if f == ScaleSmooth && !right {
+		interp = draw.CatmullRom
+	}
+	interp.Transform(img, m, i, b, draw.Src, nil)
+
+	return img
+}
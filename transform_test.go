@@ -0,0 +1,80 @@
+package ebitest
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformImage(t *testing.T) {
+	// 3x2 image with a different color on each pixel
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for x := range 3 {
+		for y := range 2 {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	px := func(x, y int) color.NRGBA { return color.NRGBA{uint8(x), uint8(y), 0, 255} }
+
+	tcs := []struct {
+		name string
+		tr   Transform
+		exp  [][]color.NRGBA
+	}{
+		{name: "Rotate90", tr: Transform{Angle: 90}, exp: [][]color.NRGBA{
+			{px(0, 1), px(0, 0)},
+			{px(1, 1), px(1, 0)},
+			{px(2, 1), px(2, 0)},
+		}},
+		{name: "Rotate180", tr: Transform{Angle: 180}, exp: [][]color.NRGBA{
+			{px(2, 1), px(1, 1), px(0, 1)},
+			{px(2, 0), px(1, 0), px(0, 0)},
+		}},
+		{name: "Rotate270", tr: Transform{Angle: -90}, exp: [][]color.NRGBA{
+			{px(2, 0), px(2, 1)},
+			{px(1, 0), px(1, 1)},
+			{px(0, 0), px(0, 1)},
+		}},
+		{name: "FlipH", tr: Transform{FlipH: true}, exp: [][]color.NRGBA{
+			{px(2, 0), px(1, 0), px(0, 0)},
+			{px(2, 1), px(1, 1), px(0, 1)},
+		}},
+		{name: "FlipV", tr: Transform{FlipV: true}, exp: [][]color.NRGBA{
+			{px(0, 1), px(1, 1), px(2, 1)},
+			{px(0, 0), px(1, 0), px(2, 0)},
+		}},
+		{name: "FlipHRotate90", tr: Transform{Angle: 90, FlipH: true}, exp: [][]color.NRGBA{
+			{px(2, 1), px(2, 0)},
+			{px(1, 1), px(1, 0)},
+			{px(0, 1), px(0, 0)},
+		}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ti := transformImage(img, tc.tr, ScaleSmooth).(*image.NRGBA)
+			require.Equal(t, image.Rect(0, 0, len(tc.exp[0]), len(tc.exp)), ti.Bounds())
+			for y, row := range tc.exp {
+				for x, c := range row {
+					assert.Equal(t, c, ti.NRGBAAt(x, y), "pixel %d,%d", x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectorTransforms(t *testing.T) {
+	s := NewFromImage(image.NewNRGBA(image.Rect(0, 0, 1, 1)))
+	assert.Equal(t, []Transform{{}}, s.transforms())
+
+	s.WithRotations(90, 360, -90).WithFlips(true, false)
+	assert.Equal(t, []Transform{
+		{}, {FlipH: true},
+		{Angle: 90}, {Angle: 90, FlipH: true},
+		{Angle: 270}, {Angle: 270, FlipH: true},
+	}, s.transforms())
+	assert.Equal(t, "rotated 270° and flipped horizontally", Transform{Angle: -90, FlipH: true}.String())
+}
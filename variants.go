@@ -0,0 +1,104 @@
+package ebitest
+
+import (
+	"image"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// ScaleFilter is the interpolation used when scaling a Selector
+type ScaleFilter int
+
+const (
+	// ScaleNearest uses nearest-neighbour, which keeps the
+	// pixels sharp so it's the one to use with pixel-art
+	ScaleNearest ScaleFilter = iota
+
+	// ScaleSmooth uses Catmull-Rom, which is the one to use
+	// with regular assets
+	ScaleSmooth
+)
+
+// variant is one of the forms in which a Selector can be on the screen
+type variant struct {
+	img       image.Image
+	scale     float64
+	transform Transform
+}
+
+// variants returns all the variants to search for sel, which are
+// all the images of the Selector at all the scales and transforms
+func (e *Ebitest) variants(sel *Selector) []variant {
+	vars := make([]variant, 0)
+	for _, sc := range e.scales(sel) {
+		for _, tr := range sel.transforms() {
+			for _, img := range sel.matchImages() {
+				vars = append(vars, variant{
+					img:       transformImage(scaleImage(img, sc, e.scaleFilter(sel)), tr, e.scaleFilter(sel)),
+					scale:     sc,
+					transform: tr,
+				})
+			}
+		}
+	}
+	return vars
+}
+
+// scales returns the scales in which sel has to be searched, the ones
+// of the Selector have priority over the default ones
+func (e *Ebitest) scales(sel *Selector) []float64 {
+	scales := []float64{1}
+	if len(sel.scales) != 0 {
+		scales = sel.scales
+	} else if len(e.options.scales) != 0 {
+		scales = e.options.scales
+	}
+
+	if e.options.autoScale {
+		if ls := e.game.GetLayoutScale(); ls != 0 && !containsScale(scales, ls) {
+			scales = append(append([]float64{}, scales...), ls)
+		}
+	}
+
+	return scales
+}
+
+// scaleFilter returns the ScaleFilter to use for sel, the one
+// of the Selector has priority over the default one
+func (e *Ebitest) scaleFilter(sel *Selector) ScaleFilter {
+	if sel.scaleFilter != nil {
+		return *sel.scaleFilter
+	}
+	return e.options.scaleFilter
+}
+
+// containsScale checks if s is on scales
+func containsScale(scales []float64, s float64) bool {
+	for _, sc := range scales {
+		if math.Abs(sc-s) < 1e-9 {
+			return true
+		}
+	}
+	return false
+}
+
+// scaleImage returns i scaled by s using the filter f
+func scaleImage(i image.Image, s float64, f ScaleFilter) image.Image {
+	if s == 1 || s <= 0 {
+		return i
+	}
+
+	b := i.Bounds()
+	w := max(1, int(math.Round(float64(b.Dx())*s)))
+	h := max(1, int(math.Round(float64(b.Dy())*s)))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	var interp draw.Interpolator = draw.NearestNeighbor
+	if f == ScaleSmooth {
+		interp = draw.CatmullRom
+	}
+	interp.Scale(img, img.Bounds(), i, b, draw.Src, nil)
+
+	return img
+}
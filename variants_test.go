@@ -0,0 +1,91 @@
+package ebitest
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutScale(t *testing.T) {
+	// A pixel-art game on a bigger window
+	assert.Equal(t, 0.5, layoutScale(320, 640, 1))
+	// A HiDPI game that has a logical size of the device pixels
+	assert.Equal(t, 1.0, layoutScale(1600, 800, 2))
+	// A game that does not take into account the device scale
+	assert.Equal(t, 0.5, layoutScale(800, 800, 2))
+	assert.Equal(t, 1.0, layoutScale(800, 800, 0))
+	assert.Equal(t, 0.0, layoutScale(800, 0, 1))
+	assert.Equal(t, 0.0, layoutScale(0, 800, 1))
+}
+
+func TestScales(t *testing.T) {
+	e := &Ebitest{game: &Game{}}
+	sel := NewFromImage(image.NewNRGBA(image.Rect(0, 0, 1, 1)))
+	assert.Equal(t, []float64{1}, e.scales(sel))
+
+	e.options.scales = []float64{1, 2}
+	assert.Equal(t, []float64{1, 2}, e.scales(sel))
+	assert.Equal(t, []float64{0.5}, e.scales(NewFromImage(sel.img).WithScales(0.5)))
+
+	// The auto scale is only added if it's known and not already there
+	e.options.autoScale = true
+	assert.Equal(t, []float64{1, 2}, e.scales(sel))
+	e.game.layoutScale = 2
+	assert.Equal(t, []float64{1, 2}, e.scales(sel))
+	e.game.layoutScale = 0.5
+	assert.Equal(t, []float64{1, 2, 0.5}, e.scales(sel))
+	assert.Equal(t, []float64{1, 2}, e.options.scales)
+
+	assert.True(t, containsScale([]float64{1, 0.1 + 0.2}, 0.3))
+	assert.False(t, containsScale([]float64{1, 2}, 0.5))
+	assert.False(t, containsScale(nil, 1))
+}
+
+func TestVariantsScale(t *testing.T) {
+	e := &Ebitest{}
+	sc := newTestScreen(120, 80)
+	rect := image.Rect(40, 20, 60, 35)
+	asset := sc.SubImage(rect)
+
+	tcs := []struct {
+		name  string
+		img   image.Image
+		scale float64
+	}{
+		{name: "Bigger", img: scaleImage(asset, 2, ScaleNearest), scale: 0.5},
+		{name: "Same", img: asset, scale: 1},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			sel := NewFromImage(tc.img).WithScales(1, 0.5)
+			sels, _ := e.findSelectors(sc, sel, !findAllSelectors, emptyRec)
+			require.Len(t, sels, 1)
+			assert.Equal(t, rect, sels[0].Rec())
+			assert.Equal(t, tc.scale, sels[0].Scale())
+			assert.Equal(t, 1.0, sels[0].Score())
+		})
+	}
+
+	// The scaled variants have the size of the scale
+	vars := e.variants(NewFromImage(asset).WithScales(1, 1.5))
+	require.Len(t, vars, 2)
+	assert.Equal(t, image.Pt(20, 15), vars[0].img.Bounds().Size())
+	assert.Equal(t, image.Pt(30, 23), vars[1].img.Bounds().Size())
+	assert.Equal(t, 1.5, vars[1].scale)
+
+	// With the smooth filter the resized template does not match exactly
+	// but it's closer than without scaling it with the same filter
+	ct := ColorTolerance{Delta: 40}
+	score := func(sel *Selector) float64 {
+		tmpl := e.template(sel, e.variants(sel)[0])
+		m, _ := tmpl.matchAt(sc.SubImage(rect).(*image.NRGBA), 0, 0, ct, tmpl.total())
+		return m.score
+	}
+	smooth := score(NewFromImage(scaleImage(asset, 2, ScaleSmooth)).WithScales(0.5).WithScaleFilter(ScaleSmooth))
+	nearest := score(NewFromImage(scaleImage(asset, 2, ScaleSmooth)).WithScales(0.5))
+	assert.Less(t, smooth, 1.0)
+	assert.Greater(t, smooth, nearest)
+}